@@ -4,18 +4,20 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+
+	"backend/utils/responses"
 )
 
+// version is injected at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
 func main() {
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintf(w, `{"message": "Go backend with utils package", "status": "running"}`)
 	})
 	
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		fmt.Fprintf(w, `{"status": "ok"}`)
-	})
+	http.Handle("/health", responses.HealthHandler(version))
 	
 	fmt.Println("Server starting on :8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
@@ -0,0 +1,98 @@
+package responses
+
+import (
+	"context"
+	"net/http"
+)
+
+// BatchResult collects per-item outcomes of a bulk operation and sends them
+// split into succeeded and failed lists.
+type BatchResult struct {
+	items []ItemResult
+}
+
+// BatchData is the data payload sent by BatchResult.
+type BatchData struct {
+	Succeeded []ItemResult `json:"succeeded"`
+	Failed    []ItemResult `json:"failed"`
+}
+
+// BatchMeta carries the item counts sent by BatchResult.
+type BatchMeta struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// NewBatchResult starts an empty batch.
+func NewBatchResult() *BatchResult {
+	return &BatchResult{}
+}
+
+// Add records an item outcome. Its status label and error type are filled in
+// from StatusCode when the batch is sent.
+func (b *BatchResult) Add(item ItemResult) *BatchResult {
+	b.items = append(b.items, item)
+	return b
+}
+
+// Succeed records a successful item with a 200 status.
+func (b *BatchResult) Succeed(id string, data interface{}) *BatchResult {
+	return b.Add(ItemResult{ID: id, StatusCode: http.StatusOK, Data: data})
+}
+
+// Fail records a failed item. An empty message uses the status default.
+func (b *BatchResult) Fail(id string, statusCode int, message string, details map[string]string) *BatchResult {
+	item := ItemResult{ID: id, StatusCode: statusCode, Message: message}
+	if details != nil {
+		item.Error = &ErrorInfo{Details: details}
+	}
+	return b.Add(item)
+}
+
+// Send responds 200 when every item succeeded and 207 otherwise, with the
+// items under data.succeeded and data.failed and their counts under meta. The
+// top-level status follows MultiStatus: "success", "error", or "partial".
+func (b *BatchResult) Send(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+
+	cfg := configFor(r)
+
+	data := BatchData{Succeeded: []ItemResult{}, Failed: []ItemResult{}}
+	for _, item := range b.items {
+		item = normalizeItemResult(ctx, cfg, item)
+		if item.Status == "error" {
+			data.Failed = append(data.Failed, item)
+		} else {
+			data.Succeeded = append(data.Succeeded, item)
+		}
+	}
+
+	meta := BatchMeta{
+		Total:     len(b.items),
+		Succeeded: len(data.Succeeded),
+		Failed:    len(data.Failed),
+	}
+
+	statusCode := http.StatusOK
+	status := "success"
+	switch {
+	case meta.Failed == 0:
+	case meta.Succeeded == 0:
+		statusCode = http.StatusMultiStatus
+		status = "error"
+	default:
+		statusCode = http.StatusMultiStatus
+		status = "partial"
+	}
+
+	send(w, r, responseSpec{
+		statusCode: statusCode,
+		data:       data,
+		meta:       meta,
+		status:     status,
+	})
+}
@@ -0,0 +1,112 @@
+package responses
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBatchResult_Mixed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/bulk", nil)
+
+	NewBatchResult().
+		Succeed("1", map[string]string{"name": "ada"}).
+		Fail("2", http.StatusConflict, "Email already registered", nil).
+		Add(ItemResult{ID: "3", StatusCode: http.StatusCreated}).
+		Fail("4", http.StatusBadRequest, "", map[string]string{"field": "email"}).
+		Send(rec, req)
+
+	if rec.Code != http.StatusMultiStatus {
+		t.Errorf("Expected code %d, got %d", http.StatusMultiStatus, rec.Code)
+	}
+
+	var body struct {
+		Status string    `json:"status"`
+		Data   BatchData `json:"data"`
+		Meta   BatchMeta `json:"meta"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if body.Status != "partial" {
+		t.Errorf("Expected status 'partial', got %q", body.Status)
+	}
+	if body.Meta != (BatchMeta{Total: 4, Succeeded: 2, Failed: 2}) {
+		t.Errorf("Unexpected meta: %+v", body.Meta)
+	}
+	if len(body.Data.Succeeded) != 2 || body.Data.Succeeded[0].ID != "1" || body.Data.Succeeded[1].ID != "3" {
+		t.Errorf("Unexpected succeeded items: %+v", body.Data.Succeeded)
+	}
+	if len(body.Data.Failed) != 2 {
+		t.Fatalf("Expected 2 failed items, got %d", len(body.Data.Failed))
+	}
+	if body.Data.Failed[0].Error == nil || body.Data.Failed[0].Error.Type != "conflict" {
+		t.Errorf("Expected item 2 to be a conflict, got %+v", body.Data.Failed[0])
+	}
+	if body.Data.Failed[1].Error == nil || body.Data.Failed[1].Error.Details["field"] != "email" {
+		t.Errorf("Expected item 4 to keep its details, got %+v", body.Data.Failed[1].Error)
+	}
+}
+
+func TestBatchResult_Aggregate(t *testing.T) {
+	tests := []struct {
+		name       string
+		batch      *BatchResult
+		wantCode   int
+		wantStatus string
+	}{
+		{"all succeeded", NewBatchResult().Succeed("1", nil).Succeed("2", nil), http.StatusOK, "success"},
+		{"all failed", NewBatchResult().Fail("1", http.StatusBadRequest, "", nil), http.StatusMultiStatus, "error"},
+		{"empty", NewBatchResult(), http.StatusOK, "success"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.batch.Send(rec, httptest.NewRequest(http.MethodPost, "/bulk", nil))
+
+			var body struct {
+				Status string                     `json:"status"`
+				Data   map[string]json.RawMessage `json:"data"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if rec.Code != tt.wantCode || body.Status != tt.wantStatus {
+				t.Errorf("Expected %d/%q, got %d/%q", tt.wantCode, tt.wantStatus, rec.Code, body.Status)
+			}
+			if string(body.Data["succeeded"]) == "null" || string(body.Data["failed"]) == "null" {
+				t.Errorf("Expected empty arrays rather than null, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestBatchResult_FailDefaultMessage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewBatchResult().
+		Fail("1", http.StatusConflict, "", nil).
+		Fail("2", http.StatusConflict, "Email already registered", nil).
+		Send(rec, httptest.NewRequest(http.MethodPost, "/users/bulk", nil))
+
+	var body struct {
+		Data BatchData `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Data.Failed) != 2 {
+		t.Fatalf("Expected 2 failed items, got %d", len(body.Data.Failed))
+	}
+
+	config, _ := GetStatusConfig(http.StatusConflict)
+	if got := body.Data.Failed[0].Message; got != config.DefaultMessage {
+		t.Errorf("Expected default message %q, got %q", config.DefaultMessage, got)
+	}
+	if got := body.Data.Failed[1].Message; got != "Email already registered" {
+		t.Errorf("Expected the given message to be kept, got %q", got)
+	}
+}
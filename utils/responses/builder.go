@@ -0,0 +1,90 @@
+package responses
+
+import "net/http"
+
+// ResponseBuilder assembles a response step by step and sends it through Write.
+type ResponseBuilder struct {
+	w          http.ResponseWriter
+	r          *http.Request
+	statusCode int
+	message    string
+	data       interface{}
+	details    map[string]string
+	meta       interface{}
+	links      map[string]string
+	headers    http.Header
+}
+
+// New starts a response for the given writer and request, defaulting to 200 OK.
+func New(w http.ResponseWriter, r *http.Request) *ResponseBuilder {
+	return &ResponseBuilder{
+		w:          w,
+		r:          r,
+		statusCode: http.StatusOK,
+		headers:    make(http.Header),
+	}
+}
+
+// Status sets the HTTP status code.
+func (b *ResponseBuilder) Status(statusCode int) *ResponseBuilder {
+	b.statusCode = statusCode
+	return b
+}
+
+// Message sets a custom message; leave unset to use the status default.
+func (b *ResponseBuilder) Message(message string) *ResponseBuilder {
+	b.message = message
+	return b
+}
+
+// Data sets the response payload.
+func (b *ResponseBuilder) Data(data interface{}) *ResponseBuilder {
+	b.data = data
+	return b
+}
+
+// Details sets the error details attached to error responses.
+func (b *ResponseBuilder) Details(details map[string]string) *ResponseBuilder {
+	b.details = details
+	return b
+}
+
+// Meta sets the top-level meta member, such as counts or pagination.
+func (b *ResponseBuilder) Meta(meta interface{}) *ResponseBuilder {
+	b.meta = meta
+	return b
+}
+
+// Links sets the top-level links member, related URLs keyed by relation.
+func (b *ResponseBuilder) Links(links map[string]string) *ResponseBuilder {
+	b.links = links
+	return b
+}
+
+// Header sets a response header, replacing any value previously set on the builder.
+// Headers managed by HTTPResponse (Content-Type, X-Content-Type-Options) take
+// precedence; a Cache-Control set here replaces the default no-store.
+func (b *ResponseBuilder) Header(key, value string) *ResponseBuilder {
+	b.headers.Set(key, value)
+	return b
+}
+
+// AddHeader adds a value to a response header, keeping earlier values, for
+// headers that may repeat such as Set-Cookie or Link.
+func (b *ResponseBuilder) AddHeader(key, value string) *ResponseBuilder {
+	b.headers.Add(key, value)
+	return b
+}
+
+// Send delegates to Write and returns the status code actually written.
+func (b *ResponseBuilder) Send() int {
+	return Write(b.w, b.r, HTTPResponseOpts{
+		StatusCode: b.statusCode,
+		Message:    b.message,
+		Data:       b.data,
+		Details:    b.details,
+		Meta:       b.meta,
+		Links:      b.links,
+		Headers:    b.headers,
+	})
+}
@@ -0,0 +1,119 @@
+package responses
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBuilder_Success(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+
+	New(rec, req).
+		Status(http.StatusCreated).
+		Data(map[string]string{"id": "42"}).
+		Header("Location", "/users/42").
+		Send()
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected code %d, got %d", http.StatusCreated, rec.Code)
+	}
+	if got := rec.Header().Get("Location"); got != "/users/42" {
+		t.Errorf("Expected Location /users/42, got %q", got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected Content-Type application/json, got %q", got)
+	}
+
+	resp := decodeResponse(t, rec.Body)
+	if resp.Status != "success" {
+		t.Errorf("Expected status 'success', got %q", resp.Status)
+	}
+	if resp.Message == "" {
+		t.Error("Expected default message for 201")
+	}
+	if resp.Data == nil {
+		t.Error("Expected data, got nil")
+	}
+}
+
+func TestBuilder_Error(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+
+	New(rec, req).
+		Status(http.StatusBadRequest).
+		Message("Invalid user").
+		Details(map[string]string{"field": "email"}).
+		Send()
+
+	resp := decodeResponse(t, rec.Body)
+	if resp.Status != "error" {
+		t.Errorf("Expected status 'error', got %q", resp.Status)
+	}
+	if resp.Message != "Invalid user" {
+		t.Errorf("Expected message 'Invalid user', got %q", resp.Message)
+	}
+	if resp.Error == nil || resp.Error.Type != "validation_error" {
+		t.Fatalf("Expected validation_error, got %+v", resp.Error)
+	}
+	if resp.Error.Details["field"] != "email" {
+		t.Errorf("Expected details field=email, got %+v", resp.Error.Details)
+	}
+}
+
+func TestBuilder_DefaultsToOK(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	New(rec, req).Send()
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected code %d, got %d", http.StatusOK, rec.Code)
+	}
+}
+
+func TestBuilder_AddHeader(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+
+	New(rec, req).
+		AddHeader("Set-Cookie", "session=abc; HttpOnly").
+		AddHeader("Set-Cookie", "theme=dark").
+		Header("X-Request-Source", "web").
+		Send()
+
+	cookies := rec.Header().Values("Set-Cookie")
+	if len(cookies) != 2 || cookies[0] != "session=abc; HttpOnly" || cookies[1] != "theme=dark" {
+		t.Errorf("Expected both Set-Cookie values, got %q", cookies)
+	}
+	if got := rec.Result().Cookies(); len(got) != 2 {
+		t.Errorf("Expected 2 parsed cookies, got %d", len(got))
+	}
+	if rec.Header().Get("X-Request-Source") != "web" {
+		t.Errorf("Expected single-valued header to be kept, got %v", rec.Header())
+	}
+}
+
+func TestBuilder_MetaAndLinks(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users?page=2", nil)
+
+	code := New(rec, req).
+		Data([]string{"ada"}).
+		Meta(map[string]int{"total": 21}).
+		Links(map[string]string{"next": "/users?page=3"}).
+		Send()
+
+	if code != http.StatusOK {
+		t.Errorf("Expected Send to return %d, got %d", http.StatusOK, code)
+	}
+	resp := decodeResponse(t, rec.Body)
+	if meta, ok := resp.Meta.(map[string]interface{}); !ok || meta["total"] != float64(21) {
+		t.Errorf("Expected meta total 21, got %v", resp.Meta)
+	}
+	if resp.Links["next"] != "/users?page=3" {
+		t.Errorf("Expected next link, got %v", resp.Links)
+	}
+}
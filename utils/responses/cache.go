@@ -0,0 +1,237 @@
+package responses
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response stored by the Cache middleware.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	StoredAt   time.Time
+	Vary       http.Header // Request headers named by the response's Vary, as sent by the request that filled the entry
+}
+
+// CacheStore is the storage backend used by Cache. Implementations must be
+// safe for concurrent use.
+type CacheStore interface {
+	Get(key string) (CachedResponse, bool)
+	Set(key string, resp CachedResponse)
+	Delete(key string)
+}
+
+// defaultMemoryCacheEntries bounds NewMemoryCacheStore.
+const defaultMemoryCacheEntries = 1024
+
+// memoryCacheStore is an in-process CacheStore holding at most maxEntries
+// entries.
+type memoryCacheStore struct {
+	mu         sync.RWMutex
+	entries    map[string]CachedResponse
+	maxEntries int
+}
+
+// NewMemoryCacheStore returns an in-memory CacheStore that holds up to 1024
+// entries.
+func NewMemoryCacheStore() CacheStore {
+	return NewMemoryCacheStoreSize(defaultMemoryCacheEntries)
+}
+
+// NewMemoryCacheStoreSize returns an in-memory CacheStore that holds up to
+// maxEntries entries, evicting the oldest when full. Values below 1 mean 1.
+func NewMemoryCacheStoreSize(maxEntries int) CacheStore {
+	return &memoryCacheStore{entries: make(map[string]CachedResponse), maxEntries: max(maxEntries, 1)}
+}
+
+func (m *memoryCacheStore) Get(key string) (CachedResponse, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	resp, ok := m.entries[key]
+	return resp, ok
+}
+
+func (m *memoryCacheStore) Set(key string, resp CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.entries[key]; !exists && len(m.entries) >= m.maxEntries {
+		// Keys come from client-controlled URLs, so the store must not grow
+		// without bound. The oldest entry is the most likely to be expired.
+		oldest := ""
+		for k, entry := range m.entries {
+			if oldest == "" || entry.StoredAt.Before(m.entries[oldest].StoredAt) {
+				oldest = k
+			}
+		}
+		delete(m.entries, oldest)
+	}
+	m.entries[key] = resp
+}
+
+func (m *memoryCacheStore) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+// cacheKey identifies a cacheable request by method, host, escaped request
+// URI, Accept, and Accept-Encoding, since bodies may be stored gzipped. The
+// fields are joined with newlines, which none of them may contain.
+func cacheKey(r *http.Request) string {
+	return strings.Join([]string{
+		r.Method,
+		r.Host,
+		r.URL.RequestURI(),
+		r.Header.Get("Accept"),
+		r.Header.Get("Accept-Encoding"),
+	}, "\n")
+}
+
+// hasCredentials reports whether r identifies a user, in which case its
+// response may be personal and must not be shared through the cache.
+func hasCredentials(r *http.Request) bool {
+	return r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != ""
+}
+
+// storable reports whether a response with header h may be shared: it sets
+// no cookies, is not marked no-store, no-cache or private, and doesn't Vary
+// on everything.
+func storable(h http.Header) bool {
+	if h.Get("Set-Cookie") != "" {
+		return false
+	}
+	for _, value := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			switch strings.ToLower(name) {
+			case "no-store", "no-cache", "private":
+				return false
+			}
+		}
+	}
+	for _, name := range varyHeaders(h) {
+		if name == "*" {
+			return false
+		}
+	}
+	return true
+}
+
+// varyHeaders lists the header names in h's Vary values.
+func varyHeaders(h http.Header) []string {
+	var names []string
+	for _, value := range h.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// varyMatches reports whether r sends the same values, for every header the
+// cached response varies on, as the request that filled the entry.
+func varyMatches(cached CachedResponse, r *http.Request) bool {
+	for _, name := range varyHeaders(cached.Header) {
+		if !slices.Equal(r.Header.Values(name), cached.Vary.Values(name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheRecorder passes writes through to the client while keeping a copy.
+type cacheRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (c *cacheRecorder) WriteHeader(statusCode int) {
+	if c.statusCode == 0 {
+		c.statusCode = statusCode
+	}
+	c.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (c *cacheRecorder) Write(p []byte) (int, error) {
+	if c.statusCode == 0 {
+		c.statusCode = http.StatusOK
+	}
+	c.body.Write(p)
+	return c.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (c *cacheRecorder) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// Cache returns middleware that keeps successful GET responses in store for
+// ttl and replays them without calling the handler. Hits carry an Age header
+// with the entry's age in seconds.
+//
+// Only shareable responses are cached. Requests with Authorization or Cookie
+// headers bypass the cache, and responses that set cookies or whose
+// Cache-Control says no-store, no-cache or private are never stored. Since
+// HTTPResponse defaults to no-store, handlers opt in by setting a
+// Cache-Control such as "public, max-age=60". A hit must also match the
+// request headers named by the response's Vary.
+//
+// Replayed bodies keep the requestId of the request that filled the cache, but
+// the X-Request-ID header is not replayed.
+func Cache(store CacheStore, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || hasCredentials(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := cacheKey(r)
+			if cached, ok := store.Get(key); ok && varyMatches(cached, r) {
+				age := time.Since(cached.StoredAt)
+				if age < ttl {
+					h := w.Header()
+					for name, values := range cached.Header {
+						h[name] = append([]string(nil), values...)
+					}
+					h.Set("Age", strconv.Itoa(int(age.Seconds())))
+					w.WriteHeader(cached.StatusCode)
+					w.Write(cached.Body)
+					return
+				}
+				store.Delete(key)
+			}
+
+			rec := &cacheRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			if rec.statusCode == http.StatusOK && storable(w.Header()) {
+				header := w.Header().Clone()
+				// The ID belongs to the request that filled the cache.
+				header.Del(configFor(r).requestIDHeader())
+				vary := http.Header{}
+				for _, name := range varyHeaders(header) {
+					if values := r.Header.Values(name); len(values) > 0 {
+						vary[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+					}
+				}
+				store.Set(key, CachedResponse{
+					StatusCode: rec.statusCode,
+					Header:     header,
+					Body:       bytes.Clone(rec.body.Bytes()),
+					StoredAt:   time.Now(),
+					Vary:       vary,
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package responses
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func countingHandler(calls *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		HTTPResponse(w, r, http.StatusOK, "", map[string]int{"call": *calls}, nil)
+	})
+}
+
+func TestCache_MissThenHit(t *testing.T) {
+	calls := 0
+	handler := Cache(NewMemoryCacheStore(), time.Minute)(countingHandler(&calls))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/reports?year=2026", nil))
+
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/reports?year=2026", nil))
+
+	if calls != 1 {
+		t.Errorf("Expected handler to run once, ran %d times", calls)
+	}
+	if first.Header().Get("Age") != "" {
+		t.Error("Expected no Age header on a miss")
+	}
+	if second.Header().Get("Age") != "0" {
+		t.Errorf("Expected Age 0 on a hit, got %q", second.Header().Get("Age"))
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("Expected cached body %q, got %q", first.Body.String(), second.Body.String())
+	}
+	if second.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected cached headers to be replayed, got %v", second.Header())
+	}
+
+	// A different query is a different entry.
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports?year=2025", nil))
+	if calls != 2 {
+		t.Errorf("Expected miss for a different query, handler ran %d times", calls)
+	}
+}
+
+func TestCache_Expiry(t *testing.T) {
+	calls := 0
+	store := NewMemoryCacheStore()
+	handler := Cache(store, time.Minute)(countingHandler(&calls))
+
+	req := httptest.NewRequest(http.MethodGet, "/reports", nil)
+	store.Set(cacheKey(req), CachedResponse{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       []byte("stale"),
+		StoredAt:   time.Now().Add(-2 * time.Minute),
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if calls != 1 {
+		t.Errorf("Expected expired entry to call the handler, ran %d times", calls)
+	}
+	if rec.Body.String() == "stale" {
+		t.Error("Expected fresh body after expiry")
+	}
+	if cached, ok := store.Get(cacheKey(req)); !ok || string(cached.Body) == "stale" {
+		t.Error("Expected the expired entry to be replaced")
+	}
+}
+
+func TestCache_SkipsNonGetAndErrors(t *testing.T) {
+	calls := 0
+	handler := Cache(NewMemoryCacheStore(), time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		HTTPResponse(w, r, http.StatusServiceUnavailable, "", nil, nil)
+	}))
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/flaky", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/flaky", nil))
+	}
+
+	if calls != 4 {
+		t.Errorf("Expected every request to reach the handler, got %d calls", calls)
+	}
+}
+
+func TestCache_SkipsCredentials(t *testing.T) {
+	calls := 0
+	handler := Cache(NewMemoryCacheStore(), time.Minute)(countingHandler(&calls))
+
+	alice := httptest.NewRequest(http.MethodGet, "/me", nil)
+	alice.Header.Set("Authorization", "Bearer alice")
+	handler.ServeHTTP(httptest.NewRecorder(), alice)
+
+	bob := httptest.NewRequest(http.MethodGet, "/me", nil)
+	bob.Header.Set("Authorization", "Bearer bob")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, bob)
+
+	withCookie := httptest.NewRequest(http.MethodGet, "/me", nil)
+	withCookie.Header.Set("Cookie", "session=carol")
+	handler.ServeHTTP(httptest.NewRecorder(), withCookie)
+
+	if calls != 3 {
+		t.Errorf("Expected every credentialed request to reach the handler, got %d calls", calls)
+	}
+	if rec.Header().Get("Age") != "" {
+		t.Error("Expected Bob's response not to come from the cache")
+	}
+}
+
+func TestCache_RespectsCacheControl(t *testing.T) {
+	for _, cacheControl := range []string{"", "private, max-age=60", "no-store", "public, no-cache"} {
+		t.Run(cacheControl, func(t *testing.T) {
+			calls := 0
+			handler := Cache(NewMemoryCacheStore(), time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if cacheControl != "" {
+					w.Header().Set("Cache-Control", cacheControl)
+				}
+				HTTPResponse(w, r, http.StatusOK, "", nil, nil)
+			}))
+
+			for i := 0; i < 2; i++ {
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports", nil))
+			}
+			if calls != 2 {
+				t.Errorf("Expected the response not to be cached, got %d calls", calls)
+			}
+		})
+	}
+}
+
+func TestCache_Vary(t *testing.T) {
+	calls := 0
+	handler := Cache(NewMemoryCacheStore(), time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		HTTPResponse(w, r, http.StatusOK, "", map[string]string{"lang": r.Header.Get("Accept-Language")}, nil)
+	}))
+
+	request := func(lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/greeting", nil)
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	request("en")
+	if rec := request("en"); rec.Header().Get("Age") == "" {
+		t.Error("Expected a hit for the same Accept-Language")
+	}
+	if rec := request("de"); rec.Header().Get("Age") != "" || decodeResponse(t, rec.Body).Data.(map[string]interface{})["lang"] != "de" {
+		t.Error("Expected a miss for a different Accept-Language")
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 handler calls, got %d", calls)
+	}
+}
+
+func TestMemoryCacheStore_Bounded(t *testing.T) {
+	store := NewMemoryCacheStoreSize(2)
+	now := time.Now()
+	store.Set("a", CachedResponse{StoredAt: now.Add(-2 * time.Minute)})
+	store.Set("b", CachedResponse{StoredAt: now.Add(-time.Minute)})
+	store.Set("c", CachedResponse{StoredAt: now})
+
+	if _, ok := store.Get("a"); ok {
+		t.Error("Expected the oldest entry to be evicted")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := store.Get(key); !ok {
+			t.Errorf("Expected entry %q to be kept", key)
+		}
+	}
+
+	// Replacing an existing key doesn't evict anything.
+	store.Set("b", CachedResponse{StoredAt: now})
+	if _, ok := store.Get("c"); !ok {
+		t.Error("Expected replacing a key to keep the other entries")
+	}
+}
+
+func TestCache_KeyedByHost(t *testing.T) {
+	calls := 0
+	handler := Cache(NewMemoryCacheStore(), time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		HTTPResponse(w, r, http.StatusOK, "", map[string]string{"host": r.Host}, nil)
+	}))
+
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://"+host+"/settings", nil))
+		if rec.Header().Get("Age") != "" || decodeResponse(t, rec.Body).Data.(map[string]interface{})["host"] != host {
+			t.Errorf("Expected a fresh response for %s", host)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 handler calls, got %d", calls)
+	}
+}
+
+func TestCacheKey_EscapedQuery(t *testing.T) {
+	escaped := httptest.NewRequest(http.MethodGet, "/files/a%3Fb=c", nil)
+	query := httptest.NewRequest(http.MethodGet, "/files/a?b=c", nil)
+	if cacheKey(escaped) == cacheKey(query) {
+		t.Errorf("Expected distinct keys, both were %q", cacheKey(query))
+	}
+}
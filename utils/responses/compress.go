@@ -0,0 +1,106 @@
+package responses
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultCompressionMinBytes is the body size below which responses are sent
+// uncompressed when Config.CompressionMinBytes is unset.
+const defaultCompressionMinBytes = 1024
+
+var gzipWriters = sync.Pool{
+	New: func() any { return gzip.NewWriter(nil) },
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip. An
+// explicit gzip entry wins over "*"; a q of 0 rules the coding out.
+func acceptsGzip(header string) bool {
+	wildcard := false
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "x-gzip" && coding != "*" {
+			continue
+		}
+
+		accepted := true
+		for _, param := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(name, "q") {
+				q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				accepted = err == nil && q > 0
+			}
+		}
+
+		if coding == "*" {
+			wildcard = accepted
+			continue
+		}
+		return accepted
+	}
+	return wildcard
+}
+
+// compressible reports whether a body of contentType is worth gzipping: text,
+// JSON, XML and JavaScript. Images, archives and other already-compressed
+// formats are left alone.
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript":
+		return true
+	}
+	return strings.HasPrefix(mediaType, "application/") &&
+		(strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml"))
+}
+
+// gzipCompress compresses body with a pooled writer.
+func gzipCompress(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(zw)
+	zw.Reset(&buf)
+
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compressBody gzips body when compression is enabled, the Content-Type
+// already set on w is compressible, the client accepts gzip and the body is at
+// least the configured size. It sets Content-Encoding and Vary and returns the
+// bytes to send.
+func compressBody(cfg Config, w http.ResponseWriter, r *http.Request, body []byte) []byte {
+	if cfg.DisableCompression || r == nil || !compressible(w.Header().Get("Content-Type")) {
+		return body
+	}
+	minBytes := cfg.CompressionMinBytes
+	if minBytes <= 0 {
+		minBytes = defaultCompressionMinBytes
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	if len(body) < minBytes || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		return body
+	}
+
+	compressed, err := gzipCompress(body)
+	if err != nil {
+		// Writing to a bytes.Buffer can't fail; send the plain body regardless.
+		return body
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	return compressed
+}
@@ -0,0 +1,188 @@
+package responses
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.8", true},
+		{"GZIP", true},
+		{"x-gzip", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0.0", false},
+		{"identity", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"gzip;q=0, *", false},
+		{"br, *;q=0.1", true},
+	}
+
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.header); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func largePayload() []map[string]string {
+	items := make([]map[string]string, 100)
+	for i := range items {
+		items[i] = map[string]string{"id": strconv.Itoa(i), "name": "item"}
+	}
+	return items
+}
+
+func TestHTTPResponse_GzipLargeBody(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, req, http.StatusOK, "", largePayload(), nil)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Expected Content-Encoding gzip, got %q", got)
+	}
+	if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Expected Vary Accept-Encoding, got %q", got)
+	}
+	if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(rec.Body.Len()) {
+		t.Errorf("Expected Content-Length %d to match the compressed body, got %s", rec.Body.Len(), got)
+	}
+
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to open gzip body: %v", err)
+	}
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to read gzip body: %v", err)
+	}
+	resp := decodeResponse(t, bytes.NewBuffer(plain))
+	if items, ok := resp.Data.([]interface{}); !ok || len(items) != 100 {
+		t.Errorf("Expected 100 items after decompression, got %v", resp.Data)
+	}
+}
+
+func TestHTTPResponse_GzipPassthrough(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            Config
+		acceptEncoding string
+		statusCode     int
+		data           interface{}
+	}{
+		{"small body", Config{}, "gzip", http.StatusOK, map[string]string{"id": "1"}},
+		{"client without gzip", Config{}, "", http.StatusOK, largePayload()},
+		{"gzip refused", Config{}, "gzip;q=0", http.StatusOK, largePayload()},
+		{"compression disabled", Config{DisableCompression: true}, "gzip", http.StatusOK, largePayload()},
+		{"below raised threshold", Config{CompressionMinBytes: 1 << 20}, "gzip", http.StatusOK, largePayload()},
+		{"no content", Config{}, "gzip", http.StatusNoContent, largePayload()},
+		{"not modified", Config{}, "gzip", http.StatusNotModified, largePayload()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+			withConfig(t, tt.cfg)
+
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			HTTPResponse(rec, req, tt.statusCode, "", tt.data, nil)
+
+			if got := rec.Header().Get("Content-Encoding"); got != "" {
+				t.Errorf("Expected no Content-Encoding, got %q", got)
+			}
+			if rec.Body.Len() > 0 && !strings.HasPrefix(rec.Body.String(), "{") {
+				t.Errorf("Expected a plain JSON body, got %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHTTPResponse_GzipKeepsSignatureOfPlainBody(t *testing.T) {
+	key := []byte("secret")
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(io.Discard, nil)), SigningKey: key})
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("X-Request-ID", "req-1")
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, req, http.StatusOK, "", largePayload(), nil)
+
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to open gzip body: %v", err)
+	}
+	plain, _ := io.ReadAll(zr)
+	if got, want := rec.Header().Get("X-Signature"), signBody(key, plain); got != want {
+		t.Errorf("Expected signature of the uncompressed body %s, got %s", want, got)
+	}
+}
+
+func TestCompressible(t *testing.T) {
+	tests := map[string]bool{
+		"application/json":                true,
+		"application/json; charset=utf-8": true,
+		"application/problem+json":        true,
+		"application/vnd.api+json":        true,
+		"application/xml; charset=utf-8":  true,
+		"text/csv":                        true,
+		"image/png":                       false,
+		"application/zip":                 false,
+		"application/octet-stream":        false,
+		"":                                false,
+	}
+	for contentType, want := range tests {
+		if got := compressible(contentType); got != want {
+			t.Errorf("compressible(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
+
+func TestCompressBody_ContentType(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), 4096)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	tests := []struct {
+		contentType string
+		wantGzip    bool
+	}{
+		{"application/json", true},
+		{"image/png", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			rec.Header().Set("Content-Type", tt.contentType)
+
+			got := compressBody(Config{}, rec, req, body)
+
+			if gzipped := rec.Header().Get("Content-Encoding") == "gzip"; gzipped != tt.wantGzip {
+				t.Errorf("Expected gzip=%v, got Content-Encoding %q", tt.wantGzip, rec.Header().Get("Content-Encoding"))
+			}
+			if !tt.wantGzip && !bytes.Equal(got, body) {
+				t.Errorf("Expected the body to pass through unchanged")
+			}
+		})
+	}
+}
@@ -1,19 +1,343 @@
 package responses
 
-import "log/slog"
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+)
 
 // Config holds configuration options for the httpresponses package.
 type Config struct {
 	Logger *slog.Logger
+
+	// ErrorLogger receives internal failures such as encode or write errors.
+	// Defaults to Logger when nil.
+	ErrorLogger *slog.Logger
+
+	// Resolver picks a request-specific config, e.g. per tenant. Returning nil
+	// uses the package config. Nil loggers in the resolved config fall back to
+	// the package loggers; all other fields are used as returned.
+	Resolver func(r *http.Request) *Config
+
+	// MinLogLevel is a floor for response log records: any lower computed level
+	// is raised to it. Nil applies no floor, so status levels and overrides are
+	// used as they are.
+	MinLogLevel *slog.Level
+
+	// ClassMessages replaces the generic fallback message for status codes
+	// without a StatusConfig, keyed by class (200, 300, 400, 500). Classes
+	// left out keep the built-in text.
+	ClassMessages map[int]string
+
+	// AllowEmptyMessage keeps an empty message empty instead of replacing it
+	// with the status default.
+	AllowEmptyMessage bool
+
+	// SigningKey, when set, signs each buffered response body with HMAC-SHA256
+	// and sends the hex digest in the X-Signature header.
+	SigningKey []byte
+
+	// ErrorDetailsKey renames the "details" member of error objects, e.g. to
+	// "fields" or "meta". Empty keeps "details", as do the reserved "type" and
+	// "retryable".
+	ErrorDetailsKey string
+
+	// ErrorsAsArray sends the error object as a one-element "errors" array
+	// instead of a single "error" object.
+	ErrorsAsArray bool
+
+	// JSONAPI shapes responses as JSON:API documents: data and meta on
+	// success, an errors array on failure, served as application/vnd.api+json.
+	// It takes precedence over ErrorsAsArray. StreamList is unaffected.
+	JSONAPI bool
+
+	// NegotiateXML honors the Accept header: the envelope is sent as
+	// application/xml when the client prefers XML, as JSON otherwise, and
+	// requests accepting neither get a 406. XML replaces JSON:API and problem
+	// details; StreamList and WriteRawJSON always send JSON.
+	NegotiateXML bool
+
+	// ForwardedForSeparators lists extra characters, besides the comma, that
+	// separate addresses in X-Forwarded-For, e.g. ";" or " \t" for
+	// misconfigured proxies.
+	ForwardedForSeparators string
+
+	// MaxDecompressedBytes caps how large a gzip request body may grow when
+	// DecompressRequest inflates it. Zero means 10 MB; negative disables the
+	// limit.
+	MaxDecompressedBytes int64
+
+	// TrustedProxies restricts which peers may report the client address via
+	// X-Forwarded-For and X-Real-IP. When set, those headers are only read if
+	// RemoteAddr falls in one of the prefixes, and X-Forwarded-For is walked
+	// right to left past trusted hops. When empty, the headers are trusted
+	// from any peer.
+	TrustedProxies []netip.Prefix
+
+	// ProblemDetails sends error responses as RFC 7807 problem details
+	// (application/problem+json) instead of the envelope. It takes precedence
+	// over JSONAPI and ErrorsAsArray for errors; success responses are
+	// unaffected.
+	ProblemDetails bool
+
+	// ProblemTypeBaseURI prefixes the error type to form the problem type URI,
+	// e.g. "https://api.example.com/problems/". Empty sends the bare type.
+	ProblemTypeBaseURI string
+
+	// MaxResponseBytes caps the encoded body size. Larger responses are
+	// replaced with a 500 and logged. Zero or negative disables the check.
+	MaxResponseBytes int
+
+	// FlushAfterWrite flushes each response as soon as it is written, for
+	// long-poll or latency-sensitive endpoints.
+	FlushAfterWrite bool
+
+	// CompressionMinBytes is the smallest body, in bytes, that is gzipped for
+	// clients that accept it. Zero or negative means 1 KB.
+	CompressionMinBytes int
+
+	// DisableCompression sends every body uncompressed.
+	DisableCompression bool
+
+	// MaxMessageLen caps the message length in runes after control characters
+	// are stripped. Zero or negative leaves the length alone.
+	MaxMessageLen int
+
+	// SecureCookies makes SetCookie force HttpOnly and Secure, and SameSite=Lax
+	// when the cookie doesn't set it.
+	SecureCookies bool
+
+	// RejectPlainHTTP makes RequireHTTPS answer plain HTTP requests with a 403
+	// instead of redirecting them.
+	RejectPlainHTTP bool
+
+	// LogFullURI logs the path with its query string instead of the path
+	// alone. Values of credential-like parameters such as token or password
+	// are replaced with "REDACTED".
+	LogFullURI bool
+
+	// MaxUserAgentLen caps the user_agent log attribute in runes. Zero or
+	// negative logs it in full.
+	MaxUserAgentLen int
+
+	// ValidateRawJSON makes WriteRawJSON check its payload and send a 500
+	// instead of invalid JSON. Meant for development; it costs a full scan.
+	ValidateRawJSON bool
+
+	// LogErrorRequestBody adds the request body captured by CaptureRequestBody
+	// to the log line of error responses.
+	LogErrorRequestBody bool
+
+	// AuthScheme and AuthRealm form the WWW-Authenticate challenge sent with
+	// 401 responses, e.g. Bearer realm="api". The scheme defaults to Bearer
+	// and the realm is left out when empty. A WWW-Authenticate header already
+	// set on the writer or passed in HTTPResponseOpts.Headers is kept.
+	AuthScheme string
+	AuthRealm  string
+
+	// RequestIDHeader names the header the request ID is read from and
+	// echoed in, e.g. "X-Correlation-ID". Empty means X-Request-ID.
+	RequestIDHeader string
+
+	// IDGenerator creates request IDs for requests without a valid
+	// request ID header. Nil uses 128 random bits, hex-encoded.
+	IDGenerator func() string
+
+	// TraceContext returns the trace and span IDs active in ctx, e.g. from
+	// OpenTelemetry's trace.SpanContextFromContext, for the trace_id and
+	// span_id log attributes. ok is false when there is no valid span. Being a
+	// plain function, it keeps tracing libraries out of this package.
+	TraceContext func(ctx context.Context) (traceID, spanID string, ok bool)
+
+	// PostProcess may mutate the envelope just before it is encoded, e.g. to
+	// stamp a correlation ID into Data. A panicking hook is logged and its
+	// changes are discarded, including those made through Error and Links;
+	// values the caller passed as Data or Meta are shared and can't be undone.
+	PostProcess func(r *http.Request, resp *Response)
+
+	xml bool // Set per request by send when the client prefers XML
 }
 
 var defaultConfig = Config{
 	Logger: slog.Default(),
 }
 
-// Only non-nil Logger will overwrite the default.
+// SetConfig replaces the package configuration. Only a nil Logger keeps the
+// current one; every other field, hooks included, is copied as given, so
+// passing nil clears a previously set ErrorLogger, Resolver or other hook.
 func SetConfig(cfg Config) {
 	if cfg.Logger != nil {
 		defaultConfig.Logger = cfg.Logger
 	}
+	defaultConfig.ErrorLogger = cfg.ErrorLogger
+	defaultConfig.Resolver = cfg.Resolver
+	defaultConfig.PostProcess = cfg.PostProcess
+	defaultConfig.TraceContext = cfg.TraceContext
+	defaultConfig.IDGenerator = cfg.IDGenerator
+	defaultConfig.MinLogLevel = nil
+	if cfg.MinLogLevel != nil {
+		floor := *cfg.MinLogLevel
+		defaultConfig.MinLogLevel = &floor
+	}
+	defaultConfig.AllowEmptyMessage = cfg.AllowEmptyMessage
+	defaultConfig.ErrorDetailsKey = cfg.ErrorDetailsKey
+	defaultConfig.ErrorsAsArray = cfg.ErrorsAsArray
+	defaultConfig.JSONAPI = cfg.JSONAPI
+	defaultConfig.ProblemDetails = cfg.ProblemDetails
+	defaultConfig.NegotiateXML = cfg.NegotiateXML
+	defaultConfig.ProblemTypeBaseURI = cfg.ProblemTypeBaseURI
+	defaultConfig.MaxResponseBytes = cfg.MaxResponseBytes
+	defaultConfig.ForwardedForSeparators = cfg.ForwardedForSeparators
+	defaultConfig.FlushAfterWrite = cfg.FlushAfterWrite
+	defaultConfig.MaxDecompressedBytes = cfg.MaxDecompressedBytes
+	defaultConfig.LogErrorRequestBody = cfg.LogErrorRequestBody
+	defaultConfig.CompressionMinBytes = cfg.CompressionMinBytes
+	defaultConfig.DisableCompression = cfg.DisableCompression
+	defaultConfig.MaxMessageLen = cfg.MaxMessageLen
+	defaultConfig.MaxUserAgentLen = cfg.MaxUserAgentLen
+	defaultConfig.LogFullURI = cfg.LogFullURI
+	defaultConfig.ValidateRawJSON = cfg.ValidateRawJSON
+	defaultConfig.RejectPlainHTTP = cfg.RejectPlainHTTP
+	defaultConfig.SecureCookies = cfg.SecureCookies
+	defaultConfig.RequestIDHeader = cfg.RequestIDHeader
+	defaultConfig.AuthScheme = cfg.AuthScheme
+	defaultConfig.AuthRealm = cfg.AuthRealm
+	// Copy so later changes to the caller's slice or map don't race with responses.
+	defaultConfig.SigningKey = bytes.Clone(cfg.SigningKey)
+	defaultConfig.ClassMessages = maps.Clone(cfg.ClassMessages)
+	defaultConfig.TrustedProxies = slices.Clone(cfg.TrustedProxies)
+}
+
+// configFor returns the config that applies to r, consulting Resolver when set.
+func configFor(r *http.Request) Config {
+	cfg := defaultConfig
+	if cfg.Logger == nil {
+		// SetConfig never stores a nil logger, but the package var can still be
+		// nil under unusual init ordering; don't let that panic a response.
+		cfg.Logger = slog.Default()
+	}
+	if r == nil || cfg.Resolver == nil {
+		return cfg
+	}
+
+	resolved := cfg.Resolver(r)
+	if resolved == nil {
+		return cfg
+	}
+
+	tenant := *resolved
+	if tenant.Logger == nil {
+		tenant.Logger = cfg.Logger
+	}
+	if tenant.ErrorLogger == nil {
+		tenant.ErrorLogger = cfg.ErrorLogger
+	}
+	tenant.Resolver = nil
+	return tenant
+}
+
+// errorLogger returns the logger used for internal failures.
+func (c Config) errorLogger() *slog.Logger {
+	if c.ErrorLogger != nil {
+		return c.ErrorLogger
+	}
+	return c.Logger
+}
+
+// responseLogLevel returns the level for a response record, applying MinLogLevel.
+func (c Config) responseLogLevel(ctx context.Context, statusCode int) slog.Level {
+	level := logLevelForStatus(ctx, statusCode)
+	if c.MinLogLevel != nil && level < *c.MinLogLevel {
+		return *c.MinLogLevel
+	}
+	return level
+}
+
+// appendTraceLogAttrs adds trace_id and span_id when TraceContext finds an
+// active span in ctx.
+func (c Config) appendTraceLogAttrs(ctx context.Context, logAttrs []slog.Attr) []slog.Attr {
+	if c.TraceContext == nil {
+		return logAttrs
+	}
+	traceID, spanID, ok := c.TraceContext(ctx)
+	if !ok {
+		return logAttrs
+	}
+	return append(logAttrs, slog.String("trace_id", traceID), slog.String("span_id", spanID))
+}
+
+// requestIDHeader returns the header that carries the request ID.
+func (c Config) requestIDHeader() string {
+	if c.RequestIDHeader == "" {
+		return defaultRequestIDHeader
+	}
+	return c.RequestIDHeader
+}
+
+// authChallenge returns the WWW-Authenticate value for 401 responses.
+func (c Config) authChallenge() string {
+	scheme := c.AuthScheme
+	if scheme == "" {
+		scheme = "Bearer"
+	}
+	if c.AuthRealm == "" {
+		return scheme
+	}
+	// An HTTP quoted-string only escapes backslashes and double quotes.
+	realm := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(c.AuthRealm)
+	return scheme + ` realm="` + realm + `"`
+}
+
+// format names the body format resp is encoded in.
+func (c Config) format(resp Response) string {
+	switch {
+	case c.xml:
+		return "xml"
+	case c.ProblemDetails && resp.Error != nil:
+		return "problem"
+	case c.JSONAPI:
+		return "jsonapi"
+	}
+	return "json"
+}
+
+// postProcess runs the PostProcess hook on a copy of resp, returning the
+// original envelope if the hook panics.
+func (c Config) postProcess(ctx context.Context, r *http.Request, resp Response) (processed Response) {
+	if c.PostProcess == nil {
+		return resp
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			c.errorLogger().LogAttrs(ctx, slog.LevelError, "Response post-processor panicked",
+				slog.Int("statusCode", resp.StatusCode),
+				slog.String("request_id", resp.RequestID),
+				slog.String("panic", fmt.Sprint(recovered)),
+			)
+			processed = resp
+		}
+	}()
+
+	processed = cloneResponse(resp)
+	c.PostProcess(r, &processed)
+	return processed
+}
+
+// cloneResponse copies resp deeply enough that edits through Error, its
+// Details, or Links don't reach the original. Data and Meta are shared.
+func cloneResponse(resp Response) Response {
+	resp.Links = maps.Clone(resp.Links)
+	if resp.Error != nil {
+		errorInfo := *resp.Error
+		errorInfo.Details = maps.Clone(errorInfo.Details)
+		resp.Error = &errorInfo
+	}
+	return resp
 }
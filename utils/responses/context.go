@@ -0,0 +1,48 @@
+package responses
+
+import "context"
+
+type contextKey int
+
+const (
+	statusOverridesKey contextKey = iota
+	noLogKey
+	requestBodyKey
+	requestIDKey
+)
+
+// WithStatusOverrides returns a copy of ctx carrying per-request status configuration.
+// Entries replace the package defaults for their status code in any response written
+// with that context, so start from GetStatusConfig when only one field should change.
+func WithStatusOverrides(ctx context.Context, overrides map[int]StatusConfig) context.Context {
+	copied := make(map[int]StatusConfig, len(overrides))
+	for code, cfg := range overrides {
+		copied[code] = cfg
+	}
+	return context.WithValue(ctx, statusOverridesKey, copied)
+}
+
+// lookupStatusConfig resolves the StatusConfig for a status code, preferring
+// overrides carried by ctx over the package defaults.
+func lookupStatusConfig(ctx context.Context, statusCode int) (StatusConfig, bool) {
+	if overrides, ok := ctx.Value(statusOverridesKey).(map[int]StatusConfig); ok {
+		if cfg, exists := overrides[statusCode]; exists {
+			return cfg, true
+		}
+	}
+	cfg, exists := statusConfigMap[statusCode]
+	return cfg, exists
+}
+
+// WithNoLog returns a copy of ctx that suppresses the response log line for
+// sensitive flows. Internal failures (marshal or write errors) are still logged
+// to the error logger.
+func WithNoLog(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noLogKey, true)
+}
+
+// loggingDisabled reports whether ctx was marked with WithNoLog.
+func loggingDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noLogKey).(bool)
+	return disabled
+}
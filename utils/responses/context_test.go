@@ -0,0 +1,64 @@
+package responses
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithStatusOverrides(t *testing.T) {
+	override, _ := GetStatusConfig(http.StatusNotFound)
+	override.DefaultMessage = "User not found"
+	override.ErrorType = "user_not_found"
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req = req.WithContext(WithStatusOverrides(req.Context(), map[int]StatusConfig{
+		http.StatusNotFound: override,
+	}))
+
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, req, http.StatusNotFound, "", nil, nil)
+
+	resp := decodeResponse(t, rec.Body)
+	if resp.Message != "User not found" {
+		t.Errorf("Expected overridden message, got %q", resp.Message)
+	}
+	if resp.Error == nil || resp.Error.Type != "user_not_found" {
+		t.Errorf("Expected overridden error type, got %+v", resp.Error)
+	}
+
+	// Requests without the override keep the package defaults.
+	rec = httptest.NewRecorder()
+	HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/orders/7", nil), http.StatusNotFound, "", nil, nil)
+
+	resp = decodeResponse(t, rec.Body)
+	if resp.Error == nil || resp.Error.Type != "not_found" {
+		t.Errorf("Expected default error type, got %+v", resp.Error)
+	}
+}
+
+func TestWithNoLog(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+
+	req := httptest.NewRequest(http.MethodPost, "/password/reset", nil)
+	req = req.WithContext(WithNoLog(req.Context()))
+
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, req, http.StatusOK, "", nil, nil)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no log record, got %q", buf.String())
+	}
+
+	// Internal failures are still reported.
+	HTTPResponse(httptest.NewRecorder(), req, http.StatusOK, "", make(chan int), nil)
+	if buf.Len() == 0 {
+		t.Error("Expected marshal failure to be logged despite WithNoLog")
+	}
+}
@@ -0,0 +1,23 @@
+package responses
+
+import "net/http"
+
+// SetCookie adds a Set-Cookie header like http.SetCookie. With
+// Config.SecureCookies set it forces HttpOnly and Secure and defaults SameSite
+// to Lax when the cookie leaves it unset. The caller's cookie is not modified.
+// Having no request, it uses the package config and never consults Resolver.
+func SetCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	if w == nil || cookie == nil {
+		return
+	}
+
+	c := *cookie
+	if configFor(nil).SecureCookies {
+		c.HttpOnly = true
+		c.Secure = true
+		if c.SameSite == 0 || c.SameSite == http.SameSiteDefaultMode {
+			c.SameSite = http.SameSiteLaxMode
+		}
+	}
+	http.SetCookie(w, &c)
+}
@@ -0,0 +1,48 @@
+package responses
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetCookie_SecureDefaults(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), SecureCookies: true})
+
+	rec := httptest.NewRecorder()
+	session := &http.Cookie{Name: "session", Value: "abc"}
+	SetCookie(rec, session)
+	SetCookie(rec, &http.Cookie{Name: "csrf", Value: "xyz", SameSite: http.SameSiteStrictMode})
+	HTTPResponse(rec, httptest.NewRequest(http.MethodPost, "/login", nil), http.StatusOK, "", nil, nil)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("Expected 2 cookies, got %d", len(cookies))
+	}
+	for _, c := range cookies {
+		if !c.HttpOnly || !c.Secure {
+			t.Errorf("Expected %s to be HttpOnly and Secure, got %+v", c.Name, c)
+		}
+	}
+	if cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Errorf("Expected SameSite=Lax by default, got %v", cookies[0].SameSite)
+	}
+	if cookies[1].SameSite != http.SameSiteStrictMode {
+		t.Errorf("Expected explicit SameSite=Strict to be kept, got %v", cookies[1].SameSite)
+	}
+	if session.HttpOnly || session.Secure {
+		t.Error("Expected caller's cookie to be left untouched")
+	}
+}
+
+func TestSetCookie_DefaultsOff(t *testing.T) {
+	rec := httptest.NewRecorder()
+	SetCookie(rec, &http.Cookie{Name: "theme", Value: "dark"})
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].HttpOnly || cookies[0].Secure {
+		t.Errorf("Expected cookie unchanged without SecureCookies, got %+v", cookies)
+	}
+}
@@ -0,0 +1,65 @@
+package responses
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipBody closes both the gzip reader and the underlying request body.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (g *gzipBody) Close() error {
+	gzErr := g.Reader.Close()
+	if err := g.body.Close(); err != nil {
+		return err
+	}
+	return gzErr
+}
+
+// defaultMaxDecompressedBytes bounds inflated request bodies when
+// Config.MaxDecompressedBytes is zero.
+const defaultMaxDecompressedBytes = 10 << 20
+
+// DecompressRequest transparently decodes gzip request bodies so handlers see
+// plaintext. Requests whose body doesn't start with a valid gzip header get a
+// 400; corruption later in the stream surfaces as a read error to the handler.
+// Reading past Config.MaxDecompressedBytes fails with *http.MaxBytesError,
+// which RespondBodyErr turns into a 413, so small gzip bombs can't expand
+// without bound.
+func DecompressRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+		if encoding != "gzip" && encoding != "x-gzip" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			HTTPResponse(w, r, http.StatusBadRequest, "The request body is not valid gzip", nil, map[string]string{
+				"content_encoding": encoding,
+			})
+			return
+		}
+
+		var body io.ReadCloser = &gzipBody{Reader: zr, body: r.Body}
+		limit := configFor(r).MaxDecompressedBytes
+		if limit == 0 {
+			limit = defaultMaxDecompressedBytes
+		}
+		if limit > 0 {
+			body = http.MaxBytesReader(w, body, limit)
+		}
+		r.Body = body
+		r.Header.Del("Content-Encoding")
+		r.Header.Del("Content-Length")
+		r.ContentLength = -1
+
+		next.ServeHTTP(w, r)
+	})
+}
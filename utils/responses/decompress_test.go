@@ -0,0 +1,108 @@
+package responses
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecompressRequest_ValidGzip(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(`{"name":"gopher"}`))
+	zw.Close()
+
+	var got string
+	handler := DecompressRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("Failed to read decompressed body: %v", err)
+		}
+		got = string(body)
+		if r.Header.Get("Content-Encoding") != "" {
+			t.Error("Expected Content-Encoding to be removed")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &compressed)
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got != `{"name":"gopher"}` {
+		t.Errorf("Expected plaintext body, got %q", got)
+	}
+}
+
+func TestDecompressRequest_CorruptGzip(t *testing.T) {
+	called := false
+	handler := DecompressRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("definitely not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if called {
+		t.Error("Expected handler not to be called for corrupt gzip")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected code %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeResponse(t, rec.Body)
+	if resp.Error == nil || resp.Error.Type != "validation_error" {
+		t.Errorf("Expected validation_error, got %+v", resp.Error)
+	}
+}
+
+func TestDecompressRequest_PassThrough(t *testing.T) {
+	var got string
+	handler := DecompressRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("plain"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "plain" {
+		t.Errorf("Expected untouched body, got %q", got)
+	}
+}
+
+func TestDecompressRequest_LimitsDecompressedSize(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), MaxDecompressedBytes: 1024})
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(bytes.Repeat([]byte("a"), 1<<20))
+	zw.Close()
+
+	handler := DecompressRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			RespondBodyErr(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected code %d, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"limit_bytes":"1024"`) {
+		t.Errorf("Expected the limit in details, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,49 @@
+package responses
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// Encode writes the standard envelope to any io.Writer, for background jobs and
+// other callers without an *http.Request. It sets no headers and logs without
+// request fields. A marshal failure is logged and returned without writing.
+func Encode(ctx context.Context, w io.Writer, statusCode int, message string, data interface{}, details map[string]string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	statusCode = validateStatusCode(statusCode)
+	cfg := configFor(nil)
+
+	resp := buildResponse(ctx, cfg, statusCode, message, data, details)
+	if cfg.AllowEmptyMessage && message == "" {
+		resp.Message = ""
+	}
+	resp.Message = sanitizeMessage(resp.Message, cfg.MaxMessageLen)
+	if resp.Error != nil {
+		resp.Error.detailsKey = cfg.ErrorDetailsKey
+	}
+	logAttrs := envelopeLogAttrs(resp)
+	if id := RequestIDFromContext(ctx); id != "" {
+		logAttrs = append(logAttrs, slog.String("request_id", id))
+	}
+
+	body, err := encodeResponse(cfg, resp, "")
+	if err != nil {
+		logAttrs = append(logAttrs, slog.Any("marshal_error", err))
+		cfg.errorLogger().LogAttrs(ctx, slog.LevelError, "Failed to marshal JSON response", logAttrs...)
+		return err
+	}
+
+	if _, err := w.Write(body); err != nil {
+		logAttrs = append(logAttrs, slog.Any("write_error", err))
+		cfg.errorLogger().LogAttrs(ctx, slog.LevelError, "Failed to write JSON response", logAttrs...)
+		return err
+	}
+
+	if !loggingDisabled(ctx) {
+		cfg.Logger.LogAttrs(ctx, cfg.responseLogLevel(ctx, resp.StatusCode), "Response encoded", logAttrs...)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package responses
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEncode_WithoutRequest(t *testing.T) {
+	var logBuf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&logBuf, nil))})
+
+	var out bytes.Buffer
+	err := Encode(context.Background(), &out, http.StatusBadRequest, "", nil, map[string]string{"job": "nightly-sync"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	resp := decodeResponse(t, &out)
+	if resp.Status != "error" || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected envelope: %+v", resp)
+	}
+	if resp.Error == nil || resp.Error.Details["job"] != "nightly-sync" {
+		t.Errorf("Expected details in envelope, got %+v", resp.Error)
+	}
+
+	logged := logBuf.String()
+	if !strings.Contains(logged, "statusCode=400") {
+		t.Errorf("Expected response to be logged, got %q", logged)
+	}
+	if strings.Contains(logged, "method=") || strings.Contains(logged, "remote_ip=") {
+		t.Errorf("Expected no request fields in log, got %q", logged)
+	}
+}
+
+func TestEncode_MarshalError(t *testing.T) {
+	var out bytes.Buffer
+	if err := Encode(context.Background(), &out, http.StatusOK, "", make(chan int), nil); err == nil {
+		t.Error("Expected marshal error")
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing written, got %q", out.String())
+	}
+}
+
+func TestEncode_SanitizesMessage(t *testing.T) {
+	var logBuf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&logBuf, nil)), MaxMessageLen: 8})
+
+	var out bytes.Buffer
+	if err := Encode(context.Background(), &out, http.StatusOK, "job\ndone\x1b[31m!", nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if resp := decodeResponse(t, &out); resp.Message != "jobdone[" {
+		t.Errorf("Expected sanitized, truncated message, got %q", resp.Message)
+	}
+	if strings.Contains(logBuf.String(), "\x1b") || strings.Count(logBuf.String(), "\n") != 1 {
+		t.Errorf("Expected control characters to stay out of the log, got %q", logBuf.String())
+	}
+}
@@ -0,0 +1,67 @@
+package responses
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// ErrNoExample is returned by ExampleJSON when no example is registered.
+var ErrNoExample = errors.New("responses: no example registered")
+
+// operationKey identifies an example registered for one API operation.
+type operationKey struct {
+	operationID string
+	statusCode  int
+}
+
+// examples holds example responses keyed by status code, and optionally by
+// operation, for docs and mocks.
+var examples = struct {
+	sync.RWMutex
+	byStatus    map[int]Response
+	byOperation map[operationKey]Response
+}{
+	byStatus:    make(map[int]Response),
+	byOperation: make(map[operationKey]Response),
+}
+
+// RegisterExample records an example response for a status code, replacing any previous one.
+func RegisterExample(statusCode int, example Response) {
+	examples.Lock()
+	defer examples.Unlock()
+	examples.byStatus[statusCode] = example
+}
+
+// GetExample returns the example registered for a status code, if any.
+func GetExample(statusCode int) (Response, bool) {
+	examples.RLock()
+	defer examples.RUnlock()
+	example, ok := examples.byStatus[statusCode]
+	return example, ok
+}
+
+// RegisterOperationExample records an example response for one operation
+// (e.g. an OpenAPI operationId) and status code, replacing any previous one.
+func RegisterOperationExample(operationID string, statusCode int, example Response) {
+	examples.Lock()
+	defer examples.Unlock()
+	examples.byOperation[operationKey{operationID, statusCode}] = example
+}
+
+// ExampleJSON returns the JSON body of the example for an operation and status
+// code, for OpenAPI examples. It falls back to the example registered for the
+// status code alone and returns ErrNoExample when there is neither.
+func ExampleJSON(operationID string, statusCode int) ([]byte, error) {
+	examples.RLock()
+	example, ok := examples.byOperation[operationKey{operationID, statusCode}]
+	if !ok {
+		example, ok = examples.byStatus[statusCode]
+	}
+	examples.RUnlock()
+
+	if !ok {
+		return nil, ErrNoExample
+	}
+	return json.Marshal(example)
+}
@@ -0,0 +1,93 @@
+package responses
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRegisterExample(t *testing.T) {
+	example := Response{
+		Status:     "error",
+		StatusCode: http.StatusConflict,
+		Message:    "Email already registered",
+		Error: &ErrorInfo{
+			Type:    "conflict",
+			Details: map[string]string{"field": "email"},
+		},
+	}
+	RegisterExample(http.StatusConflict, example)
+	t.Cleanup(func() {
+		examples.Lock()
+		delete(examples.byStatus, http.StatusConflict)
+		examples.Unlock()
+	})
+
+	got, ok := GetExample(http.StatusConflict)
+	if !ok {
+		t.Fatal("Expected registered example for 409")
+	}
+	if got.Message != example.Message || got.Error.Details["field"] != "email" {
+		t.Errorf("Expected registered example, got %+v", got)
+	}
+
+	encoded, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Expected example to be JSON-serializable: %v", err)
+	}
+	var decoded Response
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded.StatusCode != http.StatusConflict {
+		t.Errorf("Expected round-trip of example, got %+v (err %v)", decoded, err)
+	}
+
+	if _, ok := GetExample(http.StatusTeapot); ok {
+		t.Error("Expected no example for unregistered status")
+	}
+}
+
+func TestExampleJSON(t *testing.T) {
+	RegisterOperationExample("getUser", http.StatusNotFound, Response{
+		Status:     "error",
+		StatusCode: http.StatusNotFound,
+		Message:    "User not found",
+		Error:      &ErrorInfo{Type: "user_not_found"},
+	})
+	RegisterExample(http.StatusNotFound, Response{
+		Status:     "error",
+		StatusCode: http.StatusNotFound,
+		Message:    "The requested resource was not found",
+		Error:      &ErrorInfo{Type: "not_found"},
+	})
+	t.Cleanup(func() {
+		examples.Lock()
+		delete(examples.byOperation, operationKey{"getUser", http.StatusNotFound})
+		delete(examples.byStatus, http.StatusNotFound)
+		examples.Unlock()
+	})
+
+	body, err := ExampleJSON("getUser", http.StatusNotFound)
+	if err != nil {
+		t.Fatalf("Expected example for getUser 404: %v", err)
+	}
+	var decoded Response
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %s: %v", body, err)
+	}
+	if decoded.Message != "User not found" || decoded.Error == nil || decoded.Error.Type != "user_not_found" {
+		t.Errorf("Expected operation-specific example, got %+v", decoded)
+	}
+
+	// Operations without their own example use the status-wide one.
+	body, err = ExampleJSON("getOrder", http.StatusNotFound)
+	if err != nil || !json.Valid(body) {
+		t.Fatalf("Expected fallback example, got %s (err %v)", body, err)
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil || decoded.Error.Type != "not_found" {
+		t.Errorf("Expected status-wide example, got %+v", decoded)
+	}
+
+	if _, err := ExampleJSON("getUser", http.StatusTeapot); !errors.Is(err, ErrNoExample) {
+		t.Errorf("Expected ErrNoExample, got %v", err)
+	}
+}
@@ -3,20 +3,110 @@ package responses
 import (
 	"net"
 	"net/http"
+	"net/netip"
+	"net/url"
 	"strings"
 )
 
+// forwardedForIPs splits X-Forwarded-For values into entries. Commas always
+// separate entries; any rune in extraSeparators does too, for proxies that use
+// semicolons or whitespace. Repeated headers are read in order.
+func forwardedForIPs(values []string, extraSeparators string) []string {
+	var ips []string
+	for _, value := range values {
+		fields := strings.FieldsFunc(value, func(c rune) bool {
+			return c == ',' || strings.ContainsRune(extraSeparators, c)
+		})
+		for _, field := range fields {
+			if ip := strings.TrimSpace(field); ip != "" {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips
+}
+
+// trustedProxy reports whether addr falls in one of the trusted prefixes.
+func trustedProxy(proxies []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerAddr returns the host part of RemoteAddr and, when it parses, the
+// address itself.
+func peerAddr(r *http.Request) (string, netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	return host, addr, err == nil
+}
+
+// forwardingTrusted reports whether r's forwarding headers may be believed:
+// always without Config.TrustedProxies, otherwise only from a trusted peer.
+func forwardingTrusted(cfg Config, r *http.Request) bool {
+	if len(cfg.TrustedProxies) == 0 {
+		return true
+	}
+	_, peer, ok := peerAddr(r)
+	return ok && trustedProxy(cfg.TrustedProxies, peer)
+}
+
+// trustedClientIP resolves the client address when Config.TrustedProxies is
+// set. Forwarding headers are ignored unless the direct peer is trusted; the
+// X-Forwarded-For chain is then walked right to left and the first untrusted
+// hop is the client.
+func trustedClientIP(cfg Config, r *http.Request) string {
+	host, peer, ok := peerAddr(r)
+	if !ok {
+		return r.RemoteAddr
+	}
+	if !trustedProxy(cfg.TrustedProxies, peer) {
+		return host
+	}
+
+	hops := forwardedForIPs(r.Header.Values("X-Forwarded-For"), cfg.ForwardedForSeparators)
+	client := host
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			// A malformed hop can't be vouched for; stop at the last good one.
+			return client
+		}
+		client = hops[i]
+		if !trustedProxy(cfg.TrustedProxies, addr) {
+			return client
+		}
+	}
+	if len(hops) > 0 {
+		return client
+	}
+
+	if xRealIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); xRealIP != "" {
+		if net.ParseIP(xRealIP) != nil {
+			return xRealIP
+		}
+	}
+	return host
+}
+
 // getClientIP attempts to get the real client IP address from HTTP headers or RemoteAddr.
-func getClientIP(r *http.Request) string {
+func getClientIP(cfg Config, r *http.Request) string {
+	if len(cfg.TrustedProxies) > 0 {
+		return trustedClientIP(cfg, r)
+	}
+
 	// Check X-Forwarded-For header (may contain multiple IPs)
-	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-		ips := strings.Split(forwarded, ",")
-		// Take the first valid IP address
-		for _, ip := range ips {
-			ip = strings.TrimSpace(ip)
-			if net.ParseIP(ip) != nil {
-				return ip
-			}
+	// Take the first valid IP address
+	for _, ip := range forwardedForIPs(r.Header.Values("X-Forwarded-For"), cfg.ForwardedForSeparators) {
+		if net.ParseIP(ip) != nil {
+			return ip
 		}
 	}
 
@@ -39,12 +129,65 @@ func getClientIP(r *http.Request) string {
 	return r.RemoteAddr
 }
 
+// routeFor returns the pattern the request was routed by, falling back to the raw path.
+// http.ServeMux records the matched pattern on the request.
+func routeFor(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	return r.URL.Path
+}
+
+// redactedQueryParams are query, form and JSON body fields whose values are
+// never logged.
+var redactedQueryParams = map[string]bool{
+	"access_token":  true,
+	"api_key":       true,
+	"apikey":        true,
+	"client_secret": true,
+	"id_token":      true,
+	"password":      true,
+	"refresh_token": true,
+	"secret":        true,
+	"sig":           true,
+	"signature":     true,
+	"token":         true,
+}
+
+// redactQuery replaces the values of credential-like parameters in a raw
+// query with "REDACTED", keeping parameter order and encoding otherwise.
+func redactQuery(rawQuery string) string {
+	pairs := strings.Split(rawQuery, "&")
+	for i, pair := range pairs {
+		key, _, hasValue := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if hasValue && redactedQueryParams[strings.ToLower(name)] {
+			pairs[i] = key + "=REDACTED"
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// requestPath returns the path, or with Config.LogFullURI the path and the
+// redacted query string.
+func requestPath(cfg Config, r *http.Request) string {
+	if !cfg.LogFullURI || r.URL.RawQuery == "" {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + redactQuery(r.URL.RawQuery)
+}
+
 // extractRequestInfo extracts relevant request information as a struct.
-func extractRequestInfo(r *http.Request) RequestInfo {
+func extractRequestInfo(cfg Config, r *http.Request) RequestInfo {
 	return RequestInfo{
 		Method:    r.Method,
-		Path:      r.URL.Path,
+		Path:      requestPath(cfg, r),
+		Route:     routeFor(r),
 		UserAgent: r.UserAgent(),
-		RemoteIP:  getClientIP(r),
+		RemoteIP:  getClientIP(cfg, r),
+		RequestID: requestIDFor(cfg, r),
 	}
 }
@@ -0,0 +1,72 @@
+package responses
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestGetClientIP_TrustedProxies(t *testing.T) {
+	cfg := Config{TrustedProxies: []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		xRealIP    string
+		want       string
+	}{
+		{"untrusted peer spoofing X-Forwarded-For", "203.0.113.9:4000", []string{"1.2.3.4"}, "", "203.0.113.9"},
+		{"untrusted peer spoofing X-Real-IP", "203.0.113.9:4000", nil, "1.2.3.4", "203.0.113.9"},
+		{"trusted peer, single hop", "10.0.0.1:4000", []string{"198.51.100.7"}, "", "198.51.100.7"},
+		{"spoofed entry left of the real client", "10.0.0.1:4000", []string{"1.2.3.4, 198.51.100.7, 10.0.0.2"}, "", "198.51.100.7"},
+		{"chain across repeated headers", "10.0.0.1:4000", []string{"198.51.100.7", "10.0.0.3", "10.0.0.2"}, "", "198.51.100.7"},
+		{"all hops trusted", "10.0.0.1:4000", []string{"10.0.0.3, 10.0.0.2"}, "", "10.0.0.3"},
+		{"malformed hop stops the walk", "10.0.0.1:4000", []string{"garbage, 10.0.0.2"}, "", "10.0.0.2"},
+		{"trusted peer with X-Real-IP", "10.0.0.1:4000", nil, "198.51.100.7", "198.51.100.7"},
+		{"trusted peer without headers", "10.0.0.1:4000", nil, "", "10.0.0.1"},
+		{"trusted IPv6 peer", "[fd00::1]:4000", []string{"2001:db8::7"}, "", "2001:db8::7"},
+		{"IPv4-mapped trusted peer", "[::ffff:10.0.0.1]:4000", []string{"198.51.100.7"}, "", "198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set("X-Real-IP", tt.xRealIP)
+			}
+
+			if got := getClientIP(cfg, req); got != tt.want {
+				t.Errorf("Expected client IP %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetClientIP_NoTrustedProxies(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "203.0.113.9:4000"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	if got := getClientIP(Config{}, req); got != "1.2.3.4" {
+		t.Errorf("Expected X-Forwarded-For to be trusted without TrustedProxies, got %q", got)
+	}
+}
+
+func TestSetConfig_TrustedProxiesCopied(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	withConfig(t, Config{TrustedProxies: proxies})
+
+	proxies[0] = netip.MustParsePrefix("192.168.0.0/16")
+
+	if got := defaultConfig.TrustedProxies[0].String(); got != "10.0.0.0/8" {
+		t.Errorf("Expected TrustedProxies to be copied, got %s", got)
+	}
+}
@@ -0,0 +1,58 @@
+package responses
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// fanoutHandler sends each record to every wrapped handler that accepts its level.
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+// NewFanoutHandler returns a slog.Handler that writes every record to all of
+// the given handlers, e.g. text to stdout and JSON to a file. Each handler
+// applies its own level filter. Use it with slog.New for Config.Logger.
+func NewFanoutHandler(handlers ...slog.Handler) slog.Handler {
+	return &fanoutHandler{handlers: append([]slog.Handler(nil), handlers...)}
+}
+
+func (f *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range f.handlers {
+		if !h.Enabled(ctx, record.Level) {
+			continue
+		}
+		// Clone so one handler can't affect what the next one sees.
+		if err := h.Handle(ctx, record.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return &fanoutHandler{handlers: handlers}
+}
+
+func (f *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return &fanoutHandler{handlers: handlers}
+}
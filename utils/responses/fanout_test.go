@@ -0,0 +1,55 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewFanoutHandler(t *testing.T) {
+	var textBuf, jsonBuf bytes.Buffer
+	logger := slog.New(NewFanoutHandler(
+		slog.NewTextHandler(&textBuf, nil),
+		slog.NewJSONHandler(&jsonBuf, nil),
+	))
+	withConfig(t, Config{Logger: logger})
+
+	HTTPResponse(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fanout", nil), http.StatusOK, "", nil, nil)
+
+	if !strings.Contains(textBuf.String(), "path=/fanout") {
+		t.Errorf("Expected text handler to receive record, got %q", textBuf.String())
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(jsonBuf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected JSON handler to receive record: %v (%q)", err, jsonBuf.String())
+	}
+	if record["path"] != "/fanout" {
+		t.Errorf("Expected path attr in JSON record, got %v", record)
+	}
+}
+
+func TestNewFanoutHandler_RespectsLevels(t *testing.T) {
+	var infoBuf, errorBuf bytes.Buffer
+	logger := slog.New(NewFanoutHandler(
+		slog.NewTextHandler(&infoBuf, nil),
+		slog.NewTextHandler(&errorBuf, &slog.HandlerOptions{Level: slog.LevelError}),
+	)).With("service", "api")
+
+	logger.Info("routine")
+	logger.Error("broken")
+
+	if !strings.Contains(infoBuf.String(), "routine") || !strings.Contains(infoBuf.String(), "broken") {
+		t.Errorf("Expected info handler to receive both records, got %q", infoBuf.String())
+	}
+	if strings.Contains(errorBuf.String(), "routine") || !strings.Contains(errorBuf.String(), "broken") {
+		t.Errorf("Expected error handler to receive only the error, got %q", errorBuf.String())
+	}
+	if !strings.Contains(errorBuf.String(), "service=api") {
+		t.Errorf("Expected WithAttrs to reach every handler, got %q", errorBuf.String())
+	}
+}
@@ -0,0 +1,33 @@
+package responses
+
+import (
+	"net/http"
+	"time"
+)
+
+// HealthStatus is the data payload returned by HealthHandler.
+type HealthStatus struct {
+	Status        string    `json:"status"`        // Always "ok" while the process is serving
+	Version       string    `json:"version"`       // Build version, typically injected via -ldflags
+	StartedAt     time.Time `json:"startedAt"`     // When the handler was created
+	Uptime        string    `json:"uptime"`        // Human-readable uptime (e.g. "1h2m3s")
+	UptimeSeconds float64   `json:"uptimeSeconds"` // Uptime in seconds, for dashboards
+}
+
+// HealthHandler returns a handler reporting version and uptime through the
+// standard envelope. Uptime is measured from when HealthHandler is called, so
+// create it once at startup.
+func HealthHandler(version string) http.Handler {
+	startedAt := time.Now()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uptime := time.Since(startedAt)
+		HTTPResponse(w, r, http.StatusOK, "Service is healthy", HealthStatus{
+			Status:        "ok",
+			Version:       version,
+			StartedAt:     startedAt.UTC(),
+			Uptime:        uptime.Round(time.Second).String(),
+			UptimeSeconds: uptime.Seconds(),
+		}, nil)
+	})
+}
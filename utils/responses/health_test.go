@@ -0,0 +1,72 @@
+package responses
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func fetchHealth(t *testing.T, h http.Handler) HealthStatus {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected code %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var body struct {
+		Status string       `json:"status"`
+		Data   HealthStatus `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode health response: %v", err)
+	}
+	if body.Status != "success" {
+		t.Errorf("Expected envelope status 'success', got %q", body.Status)
+	}
+	return body.Data
+}
+
+func TestHealthHandler(t *testing.T) {
+	h := HealthHandler("v1.2.3")
+
+	first := fetchHealth(t, h)
+	time.Sleep(10 * time.Millisecond)
+	second := fetchHealth(t, h)
+
+	if first.Status != "ok" {
+		t.Errorf("Expected health status 'ok', got %q", first.Status)
+	}
+	if first.Version != "v1.2.3" {
+		t.Errorf("Expected version v1.2.3, got %q", first.Version)
+	}
+	if first.StartedAt.IsZero() || first.Uptime == "" {
+		t.Errorf("Expected start time and uptime, got %+v", first)
+	}
+	if !first.StartedAt.Equal(second.StartedAt) {
+		t.Errorf("Expected stable start time, got %v and %v", first.StartedAt, second.StartedAt)
+	}
+	if second.UptimeSeconds <= first.UptimeSeconds {
+		t.Errorf("Expected uptime to increase, got %v then %v", first.UptimeSeconds, second.UptimeSeconds)
+	}
+}
+
+func TestHealthHandler_CamelCaseKeys(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HealthHandler("v1").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode health response: %v", err)
+	}
+	for _, key := range []string{"startedAt", "uptimeSeconds"} {
+		if _, ok := body.Data[key]; !ok {
+			t.Errorf("Expected %q in health data, got %v", key, body.Data)
+		}
+	}
+}
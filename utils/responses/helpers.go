@@ -0,0 +1,154 @@
+package responses
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Accepted responds 202 for asynchronously processed work. statusURL, when
+// non-empty, is sent as the Location header so clients can poll the job.
+func Accepted(w http.ResponseWriter, r *http.Request, statusURL string, data interface{}) {
+	if w != nil && statusURL != "" {
+		w.Header().Set("Location", statusURL)
+	}
+	HTTPResponse(w, r, http.StatusAccepted, "", data, nil)
+}
+
+// RespondContextErr renders the error from a cancelled request context:
+// context.Canceled becomes 499 and context.DeadlineExceeded becomes 504. Any
+// other error, including nil, is a 500.
+func RespondContextErr(w http.ResponseWriter, r *http.Request, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, context.Canceled):
+		statusCode = StatusClientClosedRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		statusCode = http.StatusGatewayTimeout
+	}
+	HTTPResponse(w, r, statusCode, "", nil, nil)
+}
+
+// RespondBodyErr renders an error from reading or decoding the request body:
+// a body over an http.MaxBytesReader limit, including DecompressRequest's,
+// becomes 413 with the limit under details, and anything else is a 400.
+func RespondBodyErr(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		HTTPResponse(w, r, http.StatusRequestEntityTooLarge, "", nil, map[string]string{
+			"limit_bytes": strconv.FormatInt(tooLarge.Limit, 10),
+		})
+		return
+	}
+	HTTPResponse(w, r, http.StatusBadRequest, "", nil, nil)
+}
+
+// SetRateLimitHeaders sets X-RateLimit-Limit, X-RateLimit-Remaining, and
+// X-RateLimit-Reset (as Unix seconds) from a limiter's state. Call it before
+// sending the response, whether that is a normal response or a 429.
+func SetRateLimitHeaders(w http.ResponseWriter, limit, remaining int, reset time.Time) {
+	if w == nil {
+		return
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+}
+
+// TooManyRequests responds 429. A positive retryAfter is sent as the
+// Retry-After header in seconds.
+func TooManyRequests(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	Write(w, r, HTTPResponseOpts{StatusCode: http.StatusTooManyRequests, RetryAfter: retryAfter})
+}
+
+// ServiceUnavailable responds 503, e.g. during maintenance. A positive
+// retryAfter is sent as the Retry-After header in seconds.
+func ServiceUnavailable(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	Write(w, r, HTTPResponseOpts{StatusCode: http.StatusServiceUnavailable, RetryAfter: retryAfter})
+}
+
+// Success responds 200 with data and the default message.
+func Success(w http.ResponseWriter, r *http.Request, data interface{}) {
+	HTTPResponse(w, r, http.StatusOK, "", data, nil)
+}
+
+// Created responds 201 with the new resource as data.
+func Created(w http.ResponseWriter, r *http.Request, data interface{}) {
+	HTTPResponse(w, r, http.StatusCreated, "", data, nil)
+}
+
+// NoContent responds 204. The security headers are set but, as for every 204,
+// no body is written.
+func NoContent(w http.ResponseWriter, r *http.Request) {
+	HTTPResponse(w, r, http.StatusNoContent, "", nil, nil)
+}
+
+// Push asks an HTTP/2 client to preload paths before the main response is
+// sent, using http.Pusher on w or on a writer it wraps via Unwrap. It does
+// nothing when server push is unavailable, e.g. over HTTP/1.1. Push failures
+// are ignored since push is only a hint.
+func Push(w http.ResponseWriter, paths ...string) {
+	for w != nil {
+		if pusher, ok := w.(http.Pusher); ok {
+			for _, path := range paths {
+				_ = pusher.Push(path, nil)
+			}
+			return
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = unwrapper.Unwrap()
+	}
+}
+
+// MergeDetails combines details maps from several sources, such as separate
+// validation layers, into a new map. When a key appears more than once the
+// last map wins. Nil maps are skipped, and the result is nil when no map has
+// entries so the details stay omitted from the response.
+func MergeDetails(details ...map[string]string) map[string]string {
+	var merged map[string]string
+	for _, d := range details {
+		if len(d) == 0 {
+			continue
+		}
+		if merged == nil {
+			merged = make(map[string]string, len(d))
+		}
+		maps.Copy(merged, d)
+	}
+	return merged
+}
+
+// BadRequest responds 400. details may be nil.
+func BadRequest(w http.ResponseWriter, r *http.Request, details map[string]string) {
+	HTTPResponse(w, r, http.StatusBadRequest, "", nil, details)
+}
+
+// Unauthorized responds 401.
+func Unauthorized(w http.ResponseWriter, r *http.Request) {
+	HTTPResponse(w, r, http.StatusUnauthorized, "", nil, nil)
+}
+
+// Forbidden responds 403.
+func Forbidden(w http.ResponseWriter, r *http.Request) {
+	HTTPResponse(w, r, http.StatusForbidden, "", nil, nil)
+}
+
+// NotFound responds 404.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	HTTPResponse(w, r, http.StatusNotFound, "", nil, nil)
+}
+
+// Conflict responds 409. details may be nil.
+func Conflict(w http.ResponseWriter, r *http.Request, details map[string]string) {
+	HTTPResponse(w, r, http.StatusConflict, "", nil, details)
+}
@@ -0,0 +1,304 @@
+package responses
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAccepted(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/exports", nil)
+
+	Accepted(rec, req, "/exports/jobs/7", map[string]string{"job_id": "7", "state": "queued"})
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected code %d, got %d", http.StatusAccepted, rec.Code)
+	}
+	if got := rec.Header().Get("Location"); got != "/exports/jobs/7" {
+		t.Errorf("Expected Location /exports/jobs/7, got %q", got)
+	}
+
+	resp := decodeResponse(t, rec.Body)
+	if resp.Status != "success" || resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Unexpected envelope: %+v", resp)
+	}
+	data, ok := resp.Data.(map[string]interface{})
+	if !ok || data["job_id"] != "7" || data["state"] != "queued" {
+		t.Errorf("Expected job description in data, got %+v", resp.Data)
+	}
+}
+
+func TestRespondContextErr(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantType string
+	}{
+		{"canceled", context.Canceled, StatusClientClosedRequest, "client_closed_request"},
+		{"deadline exceeded", context.DeadlineExceeded, http.StatusGatewayTimeout, "gateway_timeout"},
+		{"wrapped deadline", fmt.Errorf("query users: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "gateway_timeout"},
+		{"other error", fmt.Errorf("boom"), http.StatusInternalServerError, "internal_server_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RespondContextErr(rec, httptest.NewRequest(http.MethodGet, "/reports", nil), tt.err)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("Expected code %d, got %d", tt.wantCode, rec.Code)
+			}
+			if resp := decodeResponse(t, rec.Body); resp.Error == nil || resp.Error.Type != tt.wantType {
+				t.Errorf("Expected error type %q, got %+v", tt.wantType, resp.Error)
+			}
+		})
+	}
+}
+
+func TestSetRateLimitHeaders(t *testing.T) {
+	reset := time.Unix(1767225600, 0)
+	tests := []struct {
+		name          string
+		remaining     int
+		statusCode    int
+		wantRemaining string
+	}{
+		{"within quota", 41, http.StatusOK, "41"},
+		{"exhausted", -1, http.StatusTooManyRequests, "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			SetRateLimitHeaders(rec, 60, tt.remaining, reset)
+			HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/search", nil), tt.statusCode, "", nil, nil)
+
+			if rec.Code != tt.statusCode {
+				t.Errorf("Expected code %d, got %d", tt.statusCode, rec.Code)
+			}
+			if got := rec.Header().Get("X-RateLimit-Limit"); got != "60" {
+				t.Errorf("Expected limit 60, got %q", got)
+			}
+			if got := rec.Header().Get("X-RateLimit-Remaining"); got != tt.wantRemaining {
+				t.Errorf("Expected remaining %s, got %q", tt.wantRemaining, got)
+			}
+			if got := rec.Header().Get("X-RateLimit-Reset"); got != "1767225600" {
+				t.Errorf("Expected reset 1767225600, got %q", got)
+			}
+		})
+	}
+}
+
+func TestSuccessAndCreated(t *testing.T) {
+	tests := []struct {
+		name     string
+		send     func(http.ResponseWriter, *http.Request, interface{})
+		wantCode int
+	}{
+		{"Success", Success, http.StatusOK},
+		{"Created", Created, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.send(rec, httptest.NewRequest(http.MethodPost, "/users", nil), map[string]string{"id": "7"})
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("Expected code %d, got %d", tt.wantCode, rec.Code)
+			}
+			resp := decodeResponse(t, rec.Body)
+			config, _ := GetStatusConfig(tt.wantCode)
+			if resp.Status != "success" || resp.Message != config.DefaultMessage {
+				t.Errorf("Expected success with default message, got %+v", resp)
+			}
+			if data, ok := resp.Data.(map[string]interface{}); !ok || data["id"] != "7" {
+				t.Errorf("Expected data to be passed through, got %v", resp.Data)
+			}
+		})
+	}
+}
+
+func TestNoContent(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NoContent(rec, httptest.NewRequest(http.MethodDelete, "/users/7", nil))
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected code %d, got %d", http.StatusNoContent, rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected empty body, got %q", rec.Body.String())
+	}
+	if rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Error("Expected security headers on 204")
+	}
+}
+
+type fakePusher struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (p *fakePusher) Push(target string, opts *http.PushOptions) error {
+	p.pushed = append(p.pushed, target)
+	return nil
+}
+
+func TestPush(t *testing.T) {
+	pusher := &fakePusher{ResponseRecorder: httptest.NewRecorder()}
+	Push(pusher, "/static/app.css", "/static/app.js")
+
+	if len(pusher.pushed) != 2 || pusher.pushed[0] != "/static/app.css" || pusher.pushed[1] != "/static/app.js" {
+		t.Errorf("Expected both paths pushed, got %q", pusher.pushed)
+	}
+
+	// Wrapped writers are unwrapped to find the pusher.
+	pusher.pushed = nil
+	Push(&cacheRecorder{ResponseWriter: pusher}, "/static/app.css")
+	if len(pusher.pushed) != 1 {
+		t.Errorf("Expected push through wrapper, got %q", pusher.pushed)
+	}
+
+	// Writers without push support are left alone.
+	rec := httptest.NewRecorder()
+	Push(rec, "/static/app.css")
+	Push(nil, "/static/app.css")
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("Expected no effect without http.Pusher, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestErrorHelpers(t *testing.T) {
+	details := map[string]string{"field": "email"}
+	tests := []struct {
+		name        string
+		send        func(http.ResponseWriter, *http.Request)
+		wantCode    int
+		wantDetails bool
+	}{
+		{"BadRequest", func(w http.ResponseWriter, r *http.Request) { BadRequest(w, r, details) }, http.StatusBadRequest, true},
+		{"BadRequest without details", func(w http.ResponseWriter, r *http.Request) { BadRequest(w, r, nil) }, http.StatusBadRequest, false},
+		{"Unauthorized", Unauthorized, http.StatusUnauthorized, false},
+		{"Forbidden", Forbidden, http.StatusForbidden, false},
+		{"NotFound", NotFound, http.StatusNotFound, false},
+		{"Conflict", func(w http.ResponseWriter, r *http.Request) { Conflict(w, r, details) }, http.StatusConflict, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.send(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("Expected code %d, got %d", tt.wantCode, rec.Code)
+			}
+			config, _ := GetStatusConfig(tt.wantCode)
+			resp := decodeResponse(t, rec.Body)
+			if resp.Message != config.DefaultMessage {
+				t.Errorf("Expected default message %q, got %q", config.DefaultMessage, resp.Message)
+			}
+			if resp.Error == nil || resp.Error.Type != config.ErrorType {
+				t.Fatalf("Expected error type %q, got %+v", config.ErrorType, resp.Error)
+			}
+			if got := resp.Error.Details["field"] == "email"; got != tt.wantDetails {
+				t.Errorf("Expected details present=%v, got %+v", tt.wantDetails, resp.Error.Details)
+			}
+		})
+	}
+}
+
+func TestMergeDetails(t *testing.T) {
+	schema := map[string]string{"email": "is required", "name": "is too short"}
+	business := map[string]string{"email": "is already registered"}
+
+	got := MergeDetails(schema, nil, business)
+	want := map[string]string{"email": "is already registered", "name": "is too short"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if schema["email"] != "is required" {
+		t.Errorf("Expected inputs to be left untouched, got %v", schema)
+	}
+
+	if got := MergeDetails(nil, map[string]string{}); got != nil {
+		t.Errorf("Expected nil for empty inputs, got %v", got)
+	}
+	if got := MergeDetails(); got != nil {
+		t.Errorf("Expected nil without inputs, got %v", got)
+	}
+}
+
+func TestRetryAfterHelpers(t *testing.T) {
+	tests := []struct {
+		name       string
+		send       func(w http.ResponseWriter, r *http.Request)
+		wantCode   int
+		wantHeader string
+	}{
+		{"TooManyRequests", func(w http.ResponseWriter, r *http.Request) { TooManyRequests(w, r, 30*time.Second) }, http.StatusTooManyRequests, "30"},
+		{"TooManyRequests rounds up", func(w http.ResponseWriter, r *http.Request) { TooManyRequests(w, r, 1500*time.Millisecond) }, http.StatusTooManyRequests, "2"},
+		{"ServiceUnavailable", func(w http.ResponseWriter, r *http.Request) { ServiceUnavailable(w, r, 2*time.Minute) }, http.StatusServiceUnavailable, "120"},
+		{"ServiceUnavailable without duration", func(w http.ResponseWriter, r *http.Request) { ServiceUnavailable(w, r, 0) }, http.StatusServiceUnavailable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.send(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("Expected code %d, got %d", tt.wantCode, rec.Code)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantHeader {
+				t.Errorf("Expected Retry-After %q, got %q", tt.wantHeader, got)
+			}
+		})
+	}
+}
+
+func TestUnauthorized_WWWAuthenticate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		headers http.Header
+		code    int
+		want    string
+	}{
+		{"default scheme", Config{}, nil, http.StatusUnauthorized, "Bearer"},
+		{"scheme and realm", Config{AuthScheme: "Basic", AuthRealm: "api"}, nil, http.StatusUnauthorized, `Basic realm="api"`},
+		{"realm is escaped", Config{AuthRealm: `my "api"`}, nil, http.StatusUnauthorized, `Bearer realm="my \"api\""`},
+		{"per-call challenge kept", Config{AuthRealm: "api"}, http.Header{"Www-Authenticate": {`Bearer error="invalid_token"`}}, http.StatusUnauthorized, `Bearer error="invalid_token"`},
+		{"not set on 403", Config{AuthRealm: "api"}, nil, http.StatusForbidden, ""},
+		{"not set on 200", Config{AuthRealm: "api"}, nil, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, tt.cfg)
+
+			rec := httptest.NewRecorder()
+			Write(rec, httptest.NewRequest(http.MethodGet, "/", nil), HTTPResponseOpts{StatusCode: tt.code, Headers: tt.headers})
+
+			if got := rec.Header().Get("WWW-Authenticate"); got != tt.want {
+				t.Errorf("Expected WWW-Authenticate %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("Unauthorized helper", func(t *testing.T) {
+		withConfig(t, Config{AuthRealm: "api"})
+
+		rec := httptest.NewRecorder()
+		Unauthorized(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if got := rec.Header().Get("WWW-Authenticate"); got != `Bearer realm="api"` {
+			t.Errorf("Expected Bearer challenge, got %q", got)
+		}
+	})
+}
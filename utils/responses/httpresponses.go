@@ -1,10 +1,19 @@
 package responses
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
 func validateStatusCode(statusCode int) int {
@@ -14,102 +23,446 @@ func validateStatusCode(statusCode int) int {
 	return statusCode
 }
 
+// sanitizeMessage strips control characters, which could break log lines or
+// the pages a message is embedded in, and truncates to maxLen runes when
+// maxLen is positive.
+func sanitizeMessage(message string, maxLen int) string {
+	message = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, message)
 
-func HTTPResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string, data interface{}, details map[string]string) {
-	statusCode = validateStatusCode(statusCode)
-
-	var ctx context.Context
-	if r != nil {
-		ctx = r.Context()
-	} else {
-		ctx = context.Background()
-	}
+	return truncateRunes(message, maxLen)
+}
 
-	var reqInfo RequestInfo
-	if r != nil {
-		reqInfo = extractRequestInfo(r)
-	} else {
-		defaultConfig.Logger.Warn("JSON response called with nil request")
+// truncateRunes shortens s to at most maxLen runes. Zero or negative maxLen
+// leaves s alone.
+func truncateRunes(s string, maxLen int) string {
+	if maxLen > 0 {
+		if runes := []rune(s); len(runes) > maxLen {
+			return string(runes[:maxLen])
+		}
 	}
+	return s
+}
 
-	message = getMessageForStatus(statusCode, message)
+// buildResponse assembles the envelope for a validated status code.
+func buildResponse(ctx context.Context, cfg Config, statusCode int, message string, data interface{}, details map[string]string) Response {
+	message = getMessageForStatus(ctx, statusCode, message, cfg.ClassMessages)
 
 	status := "success"
 	var errorInfo *ErrorInfo
 
-	config, exists := statusConfigMap[statusCode]
-
 	if statusCode >= 400 {
 		status = "error"
 
 		errorType := "unknown_error"
-		if exists && config.ErrorType != "" {
-			errorType = config.ErrorType
+		retryable := false
+		if config, exists := lookupStatusConfig(ctx, statusCode); exists {
+			if config.ErrorType != "" {
+				errorType = config.ErrorType
+			}
+			retryable = config.Retryable
 		}
 
 		errorInfo = &ErrorInfo{
-			Type:    errorType,
-			Details: details,
+			Type:      errorType,
+			Details:   details,
+			Retryable: retryable,
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-
-	resp := Response{
+	return Response{
 		Status:     status,
 		StatusCode: statusCode,
 		Message:    message,
 		Data:       data,
 		Error:      errorInfo,
 	}
+}
 
-	// Determine log level
-	logLevel := slog.LevelInfo
-	if exists {
-		logLevel = config.LogLevel
-	} else if statusCode >= 500 {
-		logLevel = slog.LevelError
-	} else if statusCode >= 400 {
-		logLevel = slog.LevelWarn
+// encodeResponse serializes the envelope into memory so marshal failures are
+// caught before anything is written to the client. instance is the request
+// path, used by problem details.
+func encodeResponse(cfg Config, resp Response, instance string) ([]byte, error) {
+	var v interface{} = resp
+	format := cfg.format(resp)
+	switch format {
+	case "problem":
+		v = newProblemDetails(cfg, resp, instance)
+	case "jsonapi":
+		v = newJSONAPIDocument(resp)
+	default:
+		if cfg.ErrorsAsArray {
+			v = newErrorListResponse(resp)
+		}
 	}
+	if format == "xml" {
+		return encodeXML(v)
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func logLevelForStatus(ctx context.Context, statusCode int) slog.Level {
+	if config, exists := lookupStatusConfig(ctx, statusCode); exists {
+		return config.LogLevel
+	}
+	switch {
+	case statusCode >= 500:
+		return slog.LevelError
+	case statusCode >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
 
-	w.WriteHeader(statusCode)
+// setResponseHeaders sets the content and security headers. Cache-Control
+// defaults to no-store; a value the handler already set, e.g. to let Cache
+// store the response, is kept.
+func setResponseHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	}
+}
+
+// signBody returns the hex-encoded HMAC-SHA256 of body.
+func signBody(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
 
+// bodyAllowedForStatus reports whether a status may carry a body. Like
+// net/http, 1xx, 204 and 304 responses are always sent bodyless.
+func bodyAllowedForStatus(statusCode int) bool {
+	switch {
+	case statusCode >= 100 && statusCode <= 199:
+		return false
+	case statusCode == http.StatusNoContent, statusCode == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// retryAfterValue formats a Retry-After value: d rounded up to whole seconds
+// when positive, otherwise at as an HTTP-date. It is empty when neither is set.
+func retryAfterValue(d time.Duration, at time.Time) string {
+	if d > 0 {
+		return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
+	}
+	if !at.IsZero() {
+		return at.UTC().Format(http.TimeFormat)
+	}
+	return ""
+}
+
+// retryAfterAllowed reports whether Retry-After means anything for a status:
+// 429, 503, and redirects.
+func retryAfterAllowed(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests ||
+		statusCode == http.StatusServiceUnavailable ||
+		(statusCode >= 300 && statusCode <= 399)
+}
+
+// isHeadRequest reports whether r is a HEAD request. HEAD responses keep the
+// headers, including Content-Length, of the equivalent GET but send no body.
+func isHeadRequest(r *http.Request) bool {
+	return r != nil && r.Method == http.MethodHead
+}
+
+// responseLogMessage picks the log message for a status class.
+func responseLogMessage(statusCode int) string {
+	if statusCode >= 500 {
+		return "HTTP server error response sent"
+	} else if statusCode >= 400 {
+		return "HTTP client error response sent"
+	}
+	return "HTTP response sent"
+}
+
+func responseLogAttrs(resp Response, reqInfo RequestInfo) []slog.Attr {
 	logAttrs := []slog.Attr{
-		slog.Int("statusCode", statusCode),
-		slog.String("status", status),
-		slog.String("message", message),
+		slog.Int("statusCode", resp.StatusCode),
+		slog.String("status", resp.Status),
+		slog.String("message", resp.Message),
 		slog.String("method", reqInfo.Method),
 		slog.String("path", reqInfo.Path),
+		slog.String("route", reqInfo.Route),
 		slog.String("user_agent", reqInfo.UserAgent),
 		slog.String("remote_ip", reqInfo.RemoteIP),
+		slog.String("request_id", reqInfo.RequestID),
 	}
+	return appendErrorLogAttrs(logAttrs, resp)
+}
 
-	if errorInfo != nil {
+// envelopeLogAttrs describes the response alone, for callers without a request.
+func envelopeLogAttrs(resp Response) []slog.Attr {
+	logAttrs := []slog.Attr{
+		slog.Int("statusCode", resp.StatusCode),
+		slog.String("status", resp.Status),
+		slog.String("message", resp.Message),
+	}
+	return appendErrorLogAttrs(logAttrs, resp)
+}
+
+func appendErrorLogAttrs(logAttrs []slog.Attr, resp Response) []slog.Attr {
+	if resp.Error != nil {
 		logAttrs = append(logAttrs,
-			slog.String("error_type", errorInfo.Type),
-			slog.Any("error_details", errorInfo.Details),
+			slog.String("error_type", resp.Error.Type),
+			slog.Any("error_details", resp.Error.Details),
 		)
 	}
+	return logAttrs
+}
 
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-        attrs := append(logAttrs, slog.Any("encoding_error", err))
-        anyAttrs := make([]any, len(attrs))
-        for i, a := range attrs {
-            anyAttrs[i] = a
-        }
-        defaultConfig.Logger.ErrorContext(ctx, "Failed to encode JSON response", anyAttrs...)
-        return
-    }
+// appendDeadlineLogAttrs records the request deadline, typically set by a
+// timeout middleware, how much of it was left when the response was sent, and
+// whether it had already expired. Requests without a deadline are unchanged.
+func appendDeadlineLogAttrs(ctx context.Context, logAttrs []slog.Attr) []slog.Attr {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return logAttrs
+	}
+	return append(logAttrs,
+		slog.Time("deadline", deadline),
+		slog.Int64("deadline_remaining_ms", time.Until(deadline).Milliseconds()),
+		slog.Bool("timed_out", errors.Is(ctx.Err(), context.DeadlineExceeded)),
+	)
+}
 
-	logMessage := "HTTP response sent"
-	if statusCode >= 500 {
-		logMessage = "HTTP server error response sent"
-	} else if statusCode >= 400 {
-		logMessage = "HTTP client error response sent"
+// requestScope returns the context and request info for r, tolerating a nil request.
+func requestScope(cfg Config, r *http.Request) (context.Context, RequestInfo) {
+	if r == nil {
+		cfg.Logger.Warn("JSON response called with nil request")
+		return context.Background(), RequestInfo{}
 	}
+	reqInfo := extractRequestInfo(cfg, r)
+	reqInfo.UserAgent = truncateRunes(reqInfo.UserAgent, cfg.MaxUserAgentLen)
+	return r.Context(), reqInfo
+}
+
+// warnNilWriter logs a response dropped for lack of a ResponseWriter. When r
+// is known, the record goes through its context and carries the request ID
+// from AssignRequestID or a valid request header.
+func warnNilWriter(cfg Config, r *http.Request, msg string, statusCode int) {
+	if r == nil {
+		cfg.Logger.Warn(msg, slog.Int("statusCode", statusCode))
+		return
+	}
+	id := RequestIDFromContext(r.Context())
+	if header := r.Header.Get(cfg.requestIDHeader()); id == "" && validRequestID(header) {
+		id = header
+	}
+	cfg.Logger.LogAttrs(r.Context(), slog.LevelWarn, msg,
+		slog.Int("statusCode", statusCode),
+		slog.String("request_id", id),
+	)
+}
+
+// HTTPResponse sends the standard envelope. It is shorthand for Write with
+// only the status, message, data, and details set.
+func HTTPResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string, data interface{}, details map[string]string) {
+	Write(w, r, HTTPResponseOpts{
+		StatusCode: statusCode,
+		Message:    message,
+		Data:       data,
+		Details:    details,
+	})
+}
+
+// HTTPResponseOpts describes a response for Write. Zero fields are left out.
+type HTTPResponseOpts struct {
+	StatusCode int
+	Message    string // Empty uses the status default
+	Data       interface{}
+	Details    map[string]string // Sent under error.details for error statuses
+	Meta       interface{}
+	Links      map[string]string
+	Headers    http.Header   // Set before sending; managed headers take precedence
+	LogAttrs   []slog.Attr   // Extra attributes for the response log line
+	RetryAfter time.Duration // Sent as Retry-After in whole seconds for 429, 503 and 3xx
+	RetryAt    time.Time     // Sent as an HTTP-date Retry-After when RetryAfter is zero
+}
 
-	defaultConfig.Logger.LogAttrs(ctx, logLevel, logMessage, logAttrs...)
+// Write sends the standard envelope described by opts and returns the status
+// code actually written, which differs from opts.StatusCode when an invalid
+// code was replaced or encoding failed. It returns 0 when w is nil.
+func Write(w http.ResponseWriter, r *http.Request, opts HTTPResponseOpts) int {
+	return send(w, r, responseSpec{
+		statusCode: opts.StatusCode,
+		message:    opts.Message,
+		data:       opts.Data,
+		details:    opts.Details,
+		meta:       opts.Meta,
+		links:      opts.Links,
+		headers:    opts.Headers,
+		logAttrs:   opts.LogAttrs,
+		retryAfter: retryAfterValue(opts.RetryAfter, opts.RetryAt),
+	})
+}
+
+// responseSpec describes a single envelope for send.
+type responseSpec struct {
+	statusCode int
+	message    string
+	data       interface{}
+	details    map[string]string
+	meta       interface{}
+	links      map[string]string
+	headers    http.Header
+	logAttrs   []slog.Attr
+	status     string // Replaces the derived "success"/"error" label when set
+	retryAfter string // Retry-After value, sent only where retryAfterAllowed
+}
+
+// send builds, encodes, writes, and logs one envelope, returning the status
+// code written, or 0 if nothing was.
+func send(w http.ResponseWriter, r *http.Request, spec responseSpec) int {
+	statusCode := validateStatusCode(spec.statusCode)
+	cfg := configFor(r)
+
+	if w == nil {
+		warnNilWriter(cfg, r, "JSON response called with nil ResponseWriter", statusCode)
+		return 0
+	}
+
+	ctx, reqInfo := requestScope(cfg, r)
+
+	if cfg.NegotiateXML && r != nil {
+		accept := r.Header.Get("Accept")
+		xmlPreferred, ok := negotiateFormat(accept)
+		if !ok {
+			// Nothing we can produce is acceptable; answer 406 in JSON.
+			statusCode = http.StatusNotAcceptable
+			spec = responseSpec{
+				statusCode: statusCode,
+				details:    map[string]string{"accept": accept},
+				headers:    spec.headers,
+				logAttrs:   spec.logAttrs,
+			}
+		}
+		cfg.xml = xmlPreferred
+	}
+
+	resp := buildResponse(ctx, cfg, statusCode, spec.message, spec.data, spec.details)
+	resp.Meta = spec.meta
+	resp.Links = spec.links
+	resp.RequestID = reqInfo.RequestID
+	if spec.status != "" {
+		resp.Status = spec.status
+	}
+	if cfg.AllowEmptyMessage && spec.message == "" {
+		resp.Message = ""
+	}
+	resp.Message = sanitizeMessage(resp.Message, cfg.MaxMessageLen)
+	if resp.Error != nil {
+		resp.Error.detailsKey = cfg.ErrorDetailsKey
+	}
+	resp = cfg.postProcess(ctx, r, resp)
+
+	body, err := encodeResponse(cfg, resp, reqInfo.Path)
+	if err != nil {
+		// The payload can't be serialized; nothing has been written yet, so
+		// replace it with a clean 500 instead of sending a broken body.
+		logAttrs := append(responseLogAttrs(resp, reqInfo), slog.Any("marshal_error", err))
+		cfg.errorLogger().LogAttrs(ctx, slog.LevelError, "Failed to marshal JSON response", logAttrs...)
+
+		resp = buildResponse(ctx, cfg, http.StatusInternalServerError, "", nil, nil)
+		resp.RequestID = reqInfo.RequestID
+		body, _ = encodeResponse(cfg, resp, reqInfo.Path)
+	}
+
+	if cfg.MaxResponseBytes > 0 && len(body) > cfg.MaxResponseBytes {
+		logAttrs := append(responseLogAttrs(resp, reqInfo),
+			slog.Int("response_bytes", len(body)),
+			slog.Int("max_response_bytes", cfg.MaxResponseBytes),
+		)
+		cfg.errorLogger().LogAttrs(ctx, slog.LevelError, "JSON response exceeds size limit", logAttrs...)
+
+		resp = buildResponse(ctx, cfg, http.StatusInternalServerError, "", nil, nil)
+		resp.RequestID = reqInfo.RequestID
+		body, _ = encodeResponse(cfg, resp, reqInfo.Path)
+	}
+
+	h := w.Header()
+	for key, values := range spec.headers {
+		h[key] = append([]string(nil), values...)
+	}
+	setResponseHeaders(w)
+	setRequestIDHeader(cfg, w, reqInfo)
+	if resp.StatusCode == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+		w.Header().Set("WWW-Authenticate", cfg.authChallenge())
+	}
+	retryAfter := ""
+	if spec.retryAfter != "" && retryAfterAllowed(resp.StatusCode) {
+		retryAfter = spec.retryAfter
+		w.Header().Set("Retry-After", retryAfter)
+	}
+	switch cfg.format(resp) {
+	case "problem":
+		w.Header().Set("Content-Type", problemContentType)
+	case "jsonapi":
+		w.Header().Set("Content-Type", jsonAPIContentType)
+	case "xml":
+		w.Header().Set("Content-Type", xmlContentType)
+	}
+	if cfg.NegotiateXML {
+		w.Header().Add("Vary", "Accept")
+	}
+	hasBody := bodyAllowedForStatus(resp.StatusCode)
+	if hasBody {
+		if len(cfg.SigningKey) > 0 {
+			w.Header().Set("X-Signature", signBody(cfg.SigningKey, body))
+		}
+		body = compressBody(cfg, w, r, body)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
+	w.WriteHeader(resp.StatusCode)
+
+	logAttrs := appendDeadlineLogAttrs(ctx, responseLogAttrs(resp, reqInfo))
+	logAttrs = cfg.appendTraceLogAttrs(ctx, logAttrs)
+	logAttrs = append(logAttrs, slog.String("format", cfg.format(resp)))
+	if retryAfter != "" {
+		logAttrs = append(logAttrs, slog.String("retry_after", retryAfter))
+	}
+	logAttrs = append(logAttrs, spec.logAttrs...)
+	if cfg.LogErrorRequestBody && resp.StatusCode >= 400 {
+		if captured, ok := requestBodySnippet(ctx); ok {
+			logAttrs = append(logAttrs,
+				slog.String("request_body", string(captured.snippet)),
+				slog.Bool("request_body_truncated", captured.truncated),
+			)
+		}
+	}
+
+	if hasBody && !isHeadRequest(r) {
+		if _, err := w.Write(body); err != nil {
+			// Usually the client went away; the body itself was valid.
+			logAttrs = append(logAttrs, slog.Any("write_error", err))
+			cfg.errorLogger().LogAttrs(ctx, slog.LevelError, "Failed to write JSON response", logAttrs...)
+			return resp.StatusCode
+		}
+	}
+
+	if cfg.FlushAfterWrite {
+		// Writers that can't flush return http.ErrNotSupported; the bytes
+		// still go out when the handler returns.
+		_ = http.NewResponseController(w).Flush()
+	}
+
+	if !loggingDisabled(ctx) {
+		cfg.Logger.LogAttrs(ctx, cfg.responseLogLevel(ctx, resp.StatusCode), responseLogMessage(resp.StatusCode), logAttrs...)
+	}
+	return resp.StatusCode
 }
@@ -0,0 +1,68 @@
+package responses
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// benchLogger swaps in the given logger for the duration of a benchmark.
+func benchLogger(b *testing.B, logger *slog.Logger) {
+	b.Helper()
+	prev := defaultConfig
+	SetConfig(Config{Logger: logger})
+	b.Cleanup(func() { defaultConfig = prev })
+}
+
+func discardJSONLogger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(io.Discard, nil))
+}
+
+func BenchmarkHTTPResponse_Success(b *testing.B) {
+	benchLogger(b, discardJSONLogger())
+	req := httptest.NewRequest(http.MethodGet, "/bench", nil)
+	data := map[string]string{"foo": "bar"}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		rec := httptest.NewRecorder()
+		HTTPResponse(rec, req, http.StatusOK, "", data, nil)
+	}
+}
+
+func BenchmarkHTTPResponse_Error(b *testing.B) {
+	benchLogger(b, discardJSONLogger())
+	req := httptest.NewRequest(http.MethodGet, "/bench", nil)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		rec := httptest.NewRecorder()
+		HTTPResponse(rec, req, http.StatusInternalServerError, "", nil, nil)
+	}
+}
+
+func BenchmarkHTTPResponse_WithDetails(b *testing.B) {
+	benchLogger(b, discardJSONLogger())
+	req := httptest.NewRequest(http.MethodPost, "/bench", nil)
+	details := map[string]string{"field": "email", "reason": "required"}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		rec := httptest.NewRecorder()
+		HTTPResponse(rec, req, http.StatusBadRequest, "", nil, details)
+	}
+}
+
+func BenchmarkHTTPResponse_LoggingDisabled(b *testing.B) {
+	benchLogger(b, slog.New(slog.DiscardHandler))
+	req := httptest.NewRequest(http.MethodGet, "/bench", nil)
+	data := map[string]string{"foo": "bar"}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		rec := httptest.NewRecorder()
+		HTTPResponse(rec, req, http.StatusOK, "", data, nil)
+	}
+}
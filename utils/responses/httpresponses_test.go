@@ -2,11 +2,19 @@ package responses
 
 import (
     "bytes"
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
     "encoding/json"
+    "errors"
     "log/slog"
     "net/http"
     "net/http/httptest"
+    "strconv"
+    "strings"
     "testing"
+    "time"
 )
 
 // Helper to decode response body
@@ -18,6 +26,14 @@ func decodeResponse(t *testing.T, body *bytes.Buffer) Response {
     return resp
 }
 
+// withConfig applies cfg for the duration of the test and restores the previous config.
+func withConfig(t *testing.T, cfg Config) {
+    t.Helper()
+    prev := defaultConfig
+    t.Cleanup(func() { defaultConfig = prev })
+    SetConfig(cfg)
+}
+
 func TestHTTPResponse_Success(t *testing.T) {
     rec := httptest.NewRecorder()
     req := httptest.NewRequest(http.MethodGet, "/test", nil)
@@ -100,7 +116,7 @@ func TestExtractRequestInfo(t *testing.T) {
     req := httptest.NewRequest(http.MethodPut, "/info", nil)
     req.Header.Set("User-Agent", "TestAgent")
     req.RemoteAddr = "1.2.3.4:5678"
-    info := extractRequestInfo(req)
+    info := extractRequestInfo(Config{}, req)
     if info.Method != http.MethodPut {
         t.Errorf("Expected method PUT, got %s", info.Method)
     }
@@ -118,16 +134,45 @@ func TestExtractRequestInfo(t *testing.T) {
 func TestGetClientIP_XForwardedFor(t *testing.T) {
     req := httptest.NewRequest(http.MethodGet, "/", nil)
     req.Header.Set("X-Forwarded-For", "8.8.8.8, 9.9.9.9")
-    ip := getClientIP(req)
+    ip := getClientIP(Config{}, req)
     if ip != "8.8.8.8" {
         t.Errorf("Expected 8.8.8.8, got %s", ip)
     }
 }
 
+func TestGetClientIP_XForwardedForSeparators(t *testing.T) {
+    tests := []struct {
+        name       string
+        separators string
+        values     []string
+        want       string
+    }{
+        {"extra whitespace", "", []string{" \t 8.8.8.8 ,\t9.9.9.9 "}, "8.8.8.8"},
+        {"empty entries", "", []string{", ,8.8.8.8"}, "8.8.8.8"},
+        {"repeated headers", "", []string{"garbage", "8.8.8.8, 9.9.9.9"}, "8.8.8.8"},
+        {"semicolons", ";", []string{"8.8.8.8;9.9.9.9"}, "8.8.8.8"},
+        {"tabs", "\t", []string{"8.8.8.8\t9.9.9.9"}, "8.8.8.8"},
+        {"semicolons not configured", "", []string{"8.8.8.8;9.9.9.9"}, "6.6.6.6"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(http.MethodGet, "/", nil)
+            req.RemoteAddr = "6.6.6.6:1234"
+            for _, v := range tt.values {
+                req.Header.Add("X-Forwarded-For", v)
+            }
+            if ip := getClientIP(Config{ForwardedForSeparators: tt.separators}, req); ip != tt.want {
+                t.Errorf("Expected %s, got %s", tt.want, ip)
+            }
+        })
+    }
+}
+
 func TestGetClientIP_XRealIP(t *testing.T) {
     req := httptest.NewRequest(http.MethodGet, "/", nil)
     req.Header.Set("X-Real-IP", "7.7.7.7")
-    ip := getClientIP(req)
+    ip := getClientIP(Config{}, req)
     if ip != "7.7.7.7" {
         t.Errorf("Expected 7.7.7.7, got %s", ip)
     }
@@ -136,8 +181,679 @@ func TestGetClientIP_XRealIP(t *testing.T) {
 func TestGetClientIP_RemoteAddr(t *testing.T) {
     req := httptest.NewRequest(http.MethodGet, "/", nil)
     req.RemoteAddr = "6.6.6.6:1234"
-    ip := getClientIP(req)
+    ip := getClientIP(Config{}, req)
     if ip != "6.6.6.6" {
         t.Errorf("Expected 6.6.6.6, got %s", ip)
     }
-}
\ No newline at end of file
+}
+
+func TestHTTPResponse_UnmarshalableData(t *testing.T) {
+    rec := httptest.NewRecorder()
+    req := httptest.NewRequest(http.MethodGet, "/broken", nil)
+
+    HTTPResponse(rec, req, http.StatusOK, "", make(chan int), nil)
+
+    if rec.Code != http.StatusInternalServerError {
+        t.Errorf("Expected code %d, got %d", http.StatusInternalServerError, rec.Code)
+    }
+    resp := decodeResponse(t, rec.Body)
+    if resp.StatusCode != http.StatusInternalServerError {
+        t.Errorf("Expected statusCode %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+    }
+    if resp.Data != nil {
+        t.Errorf("Expected data nil, got %+v", resp.Data)
+    }
+    if resp.Error == nil || resp.Error.Type != "internal_server_error" {
+        t.Errorf("Expected internal_server_error, got %+v", resp.Error)
+    }
+}
+
+func TestSetConfig_ErrorLogger(t *testing.T) {
+    var mainBuf, errBuf bytes.Buffer
+    withConfig(t, Config{
+        Logger:      slog.New(slog.NewTextHandler(&mainBuf, nil)),
+        ErrorLogger: slog.New(slog.NewTextHandler(&errBuf, nil)),
+    })
+
+    rec := httptest.NewRecorder()
+    req := httptest.NewRequest(http.MethodGet, "/broken", nil)
+    HTTPResponse(rec, req, http.StatusOK, "", func() {}, nil)
+
+    if !strings.Contains(errBuf.String(), "Failed to marshal JSON response") {
+        t.Errorf("Expected encode failure on error logger, got %q", errBuf.String())
+    }
+    if strings.Contains(mainBuf.String(), "Failed to marshal JSON response") {
+        t.Errorf("Expected encode failure not on main logger, got %q", mainBuf.String())
+    }
+}
+
+func TestHTTPResponse_NilWriter(t *testing.T) {
+    req := httptest.NewRequest(http.MethodGet, "/", nil)
+    defer func() {
+        if rec := recover(); rec != nil {
+            t.Fatalf("Expected no panic with nil writer, got %v", rec)
+        }
+    }()
+    HTTPResponse(nil, req, http.StatusOK, "", nil, nil)
+    New(nil, req).Header("X-Test", "1").Send()
+}
+
+func TestSetConfig_Resolver(t *testing.T) {
+    var acmeBuf, globexBuf, defaultBuf bytes.Buffer
+    tenants := map[string]*Config{
+        "acme":   {Logger: slog.New(slog.NewTextHandler(&acmeBuf, nil))},
+        "globex": {Logger: slog.New(slog.NewTextHandler(&globexBuf, nil))},
+    }
+    withConfig(t, Config{
+        Logger: slog.New(slog.NewTextHandler(&defaultBuf, nil)),
+        Resolver: func(r *http.Request) *Config {
+            return tenants[r.Header.Get("X-Tenant")]
+        },
+    })
+
+    for _, tenant := range []string{"acme", "globex", ""} {
+        req := httptest.NewRequest(http.MethodGet, "/tenant", nil)
+        req.Header.Set("X-Tenant", tenant)
+        HTTPResponse(httptest.NewRecorder(), req, http.StatusOK, "tenant "+tenant, nil, nil)
+    }
+
+    if !strings.Contains(acmeBuf.String(), "tenant acme") || strings.Contains(acmeBuf.String(), "tenant globex") {
+        t.Errorf("Expected only acme record on acme logger, got %q", acmeBuf.String())
+    }
+    if !strings.Contains(globexBuf.String(), "tenant globex") || strings.Contains(globexBuf.String(), "tenant acme") {
+        t.Errorf("Expected only globex record on globex logger, got %q", globexBuf.String())
+    }
+    if !strings.Contains(defaultBuf.String(), `message="tenant "`) {
+        t.Errorf("Expected unresolved request on default logger, got %q", defaultBuf.String())
+    }
+}
+
+func TestDefaultStatusConfigs_IsCopy(t *testing.T) {
+    configs := DefaultStatusConfigs()
+    if len(configs) != len(statusConfigMap) {
+        t.Fatalf("Expected %d configs, got %d", len(statusConfigMap), len(configs))
+    }
+
+    configs[http.StatusOK] = StatusConfig{DefaultMessage: "mutated"}
+    delete(configs, http.StatusNotFound)
+
+    if cfg, _ := GetStatusConfig(http.StatusOK); cfg.DefaultMessage == "mutated" {
+        t.Error("Expected internal config to be unaffected by mutation")
+    }
+    if _, ok := GetStatusConfig(http.StatusNotFound); !ok {
+        t.Error("Expected internal config to keep 404 after delete on copy")
+    }
+}
+
+// failingWriter is a ResponseWriter whose body writes always fail, as when the client disconnects.
+type failingWriter struct {
+    header http.Header
+}
+
+func (f *failingWriter) Header() http.Header        { return f.header }
+func (f *failingWriter) WriteHeader(int)            {}
+func (f *failingWriter) Write([]byte) (int, error)  { return 0, errors.New("connection reset by peer") }
+
+func TestHTTPResponse_EncodeFailureModes(t *testing.T) {
+    var buf bytes.Buffer
+    withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+    req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+    HTTPResponse(httptest.NewRecorder(), req, http.StatusOK, "", func() {}, nil)
+    if !strings.Contains(buf.String(), "marshal_error=") || strings.Contains(buf.String(), "write_error=") {
+        t.Errorf("Expected only marshal_error for unmarshalable data, got %q", buf.String())
+    }
+
+    buf.Reset()
+    HTTPResponse(&failingWriter{header: http.Header{}}, req, http.StatusOK, "", nil, nil)
+    if !strings.Contains(buf.String(), "write_error=") || strings.Contains(buf.String(), "marshal_error=") {
+        t.Errorf("Expected only write_error for failed write, got %q", buf.String())
+    }
+}
+
+func TestSetConfig_MinLogLevel(t *testing.T) {
+    var buf bytes.Buffer
+    // The handler drops anything below Warn, so a 200 is only visible if raised.
+    logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+    req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+    withConfig(t, Config{Logger: logger})
+    HTTPResponse(httptest.NewRecorder(), req, http.StatusOK, "", nil, nil)
+    if buf.Len() != 0 {
+        t.Errorf("Expected 200 to log at Info and be dropped, got %q", buf.String())
+    }
+
+    floor := slog.LevelWarn
+    withConfig(t, Config{Logger: logger, MinLogLevel: &floor})
+    HTTPResponse(httptest.NewRecorder(), req, http.StatusOK, "", nil, nil)
+    if !strings.Contains(buf.String(), "level=WARN") {
+        t.Errorf("Expected 200 raised to Warn, got %q", buf.String())
+    }
+
+    buf.Reset()
+    HTTPResponse(httptest.NewRecorder(), req, http.StatusInternalServerError, "", nil, nil)
+    if !strings.Contains(buf.String(), "level=ERROR") {
+        t.Errorf("Expected 500 to stay at Error, got %q", buf.String())
+    }
+}
+
+func TestMinLogLevel_UnsetKeepsDebugOverride(t *testing.T) {
+    var buf bytes.Buffer
+    logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+    withConfig(t, Config{Logger: logger})
+
+    override := StatusConfig{DefaultMessage: "Already exists", LogLevel: slog.LevelDebug, ErrorType: "conflict"}
+    req := httptest.NewRequest(http.MethodPost, "/users", nil)
+    req = req.WithContext(WithStatusOverrides(req.Context(), map[int]StatusConfig{http.StatusConflict: override}))
+    HTTPResponse(httptest.NewRecorder(), req, http.StatusConflict, "", nil, nil)
+
+    if !strings.Contains(buf.String(), "level=DEBUG") {
+        t.Errorf("Expected the Debug override to be kept without a floor, got %q", buf.String())
+    }
+}
+
+func TestHTTPResponse_HeadRequest(t *testing.T) {
+    get := httptest.NewRecorder()
+    HTTPResponse(get, httptest.NewRequest(http.MethodGet, "/users", nil), http.StatusOK, "", map[string]string{"id": "1"}, nil)
+
+    head := httptest.NewRecorder()
+    HTTPResponse(head, httptest.NewRequest(http.MethodHead, "/users", nil), http.StatusOK, "", map[string]string{"id": "1"}, nil)
+
+    if head.Code != http.StatusOK {
+        t.Errorf("Expected code %d, got %d", http.StatusOK, head.Code)
+    }
+    if head.Body.Len() != 0 {
+        t.Errorf("Expected empty body for HEAD, got %q", head.Body.String())
+    }
+    if got := head.Header().Get("Content-Type"); got != "application/json" {
+        t.Errorf("Expected Content-Type application/json, got %q", got)
+    }
+    if got, want := head.Header().Get("Content-Length"), strconv.Itoa(get.Body.Len()); got != want {
+        t.Errorf("Expected Content-Length %s matching GET, got %q", want, got)
+    }
+}
+
+func TestSetConfig_PostProcess(t *testing.T) {
+    withConfig(t, Config{
+        PostProcess: func(r *http.Request, resp *Response) {
+            resp.Data = map[string]interface{}{
+                "request_id": r.Header.Get("X-Request-ID"),
+                "payload":    resp.Data,
+            }
+        },
+    })
+
+    rec := httptest.NewRecorder()
+    req := httptest.NewRequest(http.MethodGet, "/", nil)
+    req.Header.Set("X-Request-ID", "req-123")
+    HTTPResponse(rec, req, http.StatusOK, "", "hello", nil)
+
+    resp := decodeResponse(t, rec.Body)
+    data, ok := resp.Data.(map[string]interface{})
+    if !ok || data["request_id"] != "req-123" || data["payload"] != "hello" {
+        t.Errorf("Expected post-processed data, got %+v", resp.Data)
+    }
+}
+
+func TestSetConfig_PostProcessPanic(t *testing.T) {
+    withConfig(t, Config{
+        PostProcess: func(r *http.Request, resp *Response) {
+            resp.Message = "half-applied"
+            panic("boom")
+        },
+    })
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, "original", nil, nil)
+
+    resp := decodeResponse(t, rec.Body)
+    if resp.Message != "original" {
+        t.Errorf("Expected hook changes discarded after panic, got %q", resp.Message)
+    }
+}
+
+func TestHTTPResponse_LogsRoute(t *testing.T) {
+    var buf bytes.Buffer
+    withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+        HTTPResponse(w, r, http.StatusOK, "", nil, nil)
+    })
+    mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/123", nil))
+
+    if !strings.Contains(buf.String(), `route="GET /users/{id}"`) {
+        t.Errorf("Expected matched route in log, got %q", buf.String())
+    }
+    if !strings.Contains(buf.String(), "path=/users/123") {
+        t.Errorf("Expected raw path in log, got %q", buf.String())
+    }
+
+    buf.Reset()
+    HTTPResponse(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unrouted", nil), http.StatusOK, "", nil, nil)
+    if !strings.Contains(buf.String(), "route=/unrouted") {
+        t.Errorf("Expected route to fall back to path, got %q", buf.String())
+    }
+}
+
+func TestSetConfig_AllowEmptyMessage(t *testing.T) {
+    req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, req, 299, "", nil, nil)
+    if resp := decodeResponse(t, rec.Body); resp.Message != "Request completed successfully" {
+        t.Errorf("Expected class fallback message by default, got %q", resp.Message)
+    }
+
+    withConfig(t, Config{AllowEmptyMessage: true})
+
+    rec = httptest.NewRecorder()
+    HTTPResponse(rec, req, 299, "", nil, nil)
+    if resp := decodeResponse(t, rec.Body); resp.Message != "" {
+        t.Errorf("Expected empty message to be kept, got %q", resp.Message)
+    }
+
+    rec = httptest.NewRecorder()
+    HTTPResponse(rec, req, http.StatusOK, "Custom", nil, nil)
+    if resp := decodeResponse(t, rec.Body); resp.Message != "Custom" {
+        t.Errorf("Expected custom message, got %q", resp.Message)
+    }
+}
+
+func TestHTTPResponse_NilLoggerFallsBack(t *testing.T) {
+    prev := defaultConfig
+    t.Cleanup(func() { defaultConfig = prev })
+    defaultConfig.Logger = nil
+
+    defer func() {
+        if rec := recover(); rec != nil {
+            t.Fatalf("Expected no panic with nil logger, got %v", rec)
+        }
+    }()
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, "", nil, nil)
+    HTTPResponse(rec, nil, http.StatusOK, "", nil, nil)
+    if rec.Code != http.StatusOK {
+        t.Errorf("Expected code %d, got %d", http.StatusOK, rec.Code)
+    }
+}
+
+func TestSetConfig_SigningKey(t *testing.T) {
+    key := []byte("partner-secret")
+    withConfig(t, Config{SigningKey: key})
+
+    // Mutating the caller's key after SetConfig must not change signatures.
+    key[0] = 'X'
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodPost, "/webhook", nil), http.StatusOK, "", map[string]int{"id": 1}, nil)
+
+    mac := hmac.New(sha256.New, []byte("partner-secret"))
+    mac.Write(rec.Body.Bytes())
+    want := hex.EncodeToString(mac.Sum(nil))
+
+    if got := rec.Header().Get("X-Signature"); got != want {
+        t.Errorf("Expected X-Signature %s, got %q", want, got)
+    }
+}
+
+func TestSetConfig_ErrorDetailsKey(t *testing.T) {
+    withConfig(t, Config{ErrorDetailsKey: "fields"})
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodPost, "/", nil), http.StatusBadRequest, "", nil, map[string]string{"email": "required"})
+
+    var body struct {
+        Error map[string]interface{} `json:"error"`
+    }
+    if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+        t.Fatalf("Failed to decode response: %v", err)
+    }
+    errObj := body.Error
+    if errObj["type"] != "validation_error" {
+        t.Errorf("Expected validation_error, got %v", errObj["type"])
+    }
+    if _, ok := errObj["details"]; ok {
+        t.Error("Expected no 'details' key when renamed")
+    }
+    fields, ok := errObj["fields"].(map[string]interface{})
+    if !ok || fields["email"] != "required" {
+        t.Errorf("Expected details under 'fields', got %v", errObj)
+    }
+}
+
+func TestSetConfig_ErrorDetailsKeyReserved(t *testing.T) {
+    for _, key := range []string{"type", "retryable"} {
+        withConfig(t, Config{ErrorDetailsKey: key})
+
+        rec := httptest.NewRecorder()
+        HTTPResponse(rec, httptest.NewRequest(http.MethodPost, "/", nil), http.StatusBadRequest, "", nil, map[string]string{"a": "b"})
+
+        if strings.Count(rec.Body.String(), `"`+key+`"`) != 1 {
+            t.Errorf("Expected a single %q member, got %s", key, rec.Body.String())
+        }
+        resp := decodeResponse(t, rec.Body)
+        if resp.Error == nil || resp.Error.Type != "validation_error" || resp.Error.Details["a"] != "b" {
+            t.Errorf("Expected %q to fall back to details, got %+v", key, resp.Error)
+        }
+    }
+}
+
+func TestHTTPResponse_BodylessStatuses(t *testing.T) {
+    var buf bytes.Buffer
+    withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+
+    for _, code := range []int{http.StatusNoContent, http.StatusNotModified} {
+        buf.Reset()
+        rec := httptest.NewRecorder()
+        HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/resource", nil), code, "", map[string]string{"ignored": "yes"}, nil)
+
+        if rec.Code != code {
+            t.Errorf("Expected code %d, got %d", code, rec.Code)
+        }
+        if rec.Body.Len() != 0 {
+            t.Errorf("Expected empty body for %d, got %q", code, rec.Body.String())
+        }
+        if rec.Header().Get("Content-Length") != "" {
+            t.Errorf("Expected no Content-Length for %d, got %q", code, rec.Header().Get("Content-Length"))
+        }
+        if !strings.Contains(buf.String(), "status=success") || strings.Contains(buf.String(), "error_type") {
+            t.Errorf("Expected %d logged as non-error, got %q", code, buf.String())
+        }
+    }
+}
+
+func TestSetConfig_MaxResponseBytes(t *testing.T) {
+    var buf bytes.Buffer
+    withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil)), MaxResponseBytes: 256})
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/reports/huge", nil), http.StatusOK, "", strings.Repeat("x", 1024), nil)
+
+    if rec.Code != http.StatusInternalServerError {
+        t.Errorf("Expected code %d, got %d", http.StatusInternalServerError, rec.Code)
+    }
+    resp := decodeResponse(t, rec.Body)
+    if resp.Data != nil || resp.Error == nil || resp.Error.Type != "internal_server_error" {
+        t.Errorf("Expected clean 500 envelope, got %+v", resp)
+    }
+    if !strings.Contains(buf.String(), "exceeds size limit") || !strings.Contains(buf.String(), "path=/reports/huge") {
+        t.Errorf("Expected size limit log with path, got %q", buf.String())
+    }
+
+    rec = httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/small", nil), http.StatusOK, "", "ok", nil)
+    if rec.Code != http.StatusOK {
+        t.Errorf("Expected small response to pass, got %d", rec.Code)
+    }
+}
+
+func TestSetConfig_FlushAfterWrite(t *testing.T) {
+    req := httptest.NewRequest(http.MethodGet, "/poll", nil)
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, req, http.StatusOK, "", nil, nil)
+    if rec.Flushed {
+        t.Error("Expected no flush by default")
+    }
+
+    withConfig(t, Config{FlushAfterWrite: true})
+
+    rec = httptest.NewRecorder()
+    HTTPResponse(rec, req, http.StatusOK, "", nil, nil)
+    if !rec.Flushed {
+        t.Error("Expected response to be flushed")
+    }
+
+    // Writers without flush support must not break the response.
+    rec = httptest.NewRecorder()
+    HTTPResponse(struct{ http.ResponseWriter }{rec}, req, http.StatusOK, "", nil, nil)
+    if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+        t.Errorf("Expected normal response without flush support, got %d %q", rec.Code, rec.Body.String())
+    }
+}
+
+func TestHTTPResponse_SanitizesMessage(t *testing.T) {
+    var buf bytes.Buffer
+    withConfig(t, Config{Logger: slog.New(slog.NewJSONHandler(&buf, nil)), MaxMessageLen: 12})
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusBadRequest, "Bad\ninput\x1b[31m\u0085 here, truncated", nil, nil)
+
+    resp := decodeResponse(t, rec.Body)
+    if resp.Message != "Badinput[31m" {
+        t.Errorf("Expected sanitized message %q, got %q", "Badinput[31m", resp.Message)
+    }
+    var record map[string]interface{}
+    if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+        t.Fatalf("Failed to decode log record: %v", err)
+    }
+    if record["message"] != "Badinput[31m" {
+        t.Errorf("Expected sanitized message in log, got %v", record["message"])
+    }
+}
+
+func TestSetConfig_ErrorsAsArray(t *testing.T) {
+    withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), ErrorsAsArray: true})
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodPost, "/users", nil), http.StatusBadRequest, "", nil, map[string]string{"field": "email"})
+
+    var body map[string]json.RawMessage
+    if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+        t.Fatalf("Failed to decode response: %v", err)
+    }
+    if _, ok := body["error"]; ok {
+        t.Error("Expected no single error object")
+    }
+    var errs []ErrorInfo
+    if err := json.Unmarshal(body["errors"], &errs); err != nil {
+        t.Fatalf("Expected errors array, got %s: %v", body["errors"], err)
+    }
+    if len(errs) != 1 || errs[0].Type != "validation_error" || errs[0].Details["field"] != "email" {
+        t.Errorf("Expected one validation_error with details, got %+v", errs)
+    }
+
+    rec = httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/users", nil), http.StatusOK, "", "ok", nil)
+    if strings.Contains(rec.Body.String(), `"errors"`) {
+        t.Errorf("Expected no errors member on success, got %s", rec.Body.String())
+    }
+}
+
+func TestHTTPResponse_Retryable(t *testing.T) {
+    tests := []struct {
+        statusCode int
+        want       bool
+    }{
+        {http.StatusServiceUnavailable, true},
+        {http.StatusTooManyRequests, true},
+        {http.StatusGatewayTimeout, true},
+        {http.StatusBadRequest, false},
+        {http.StatusInternalServerError, false},
+    }
+
+    for _, tt := range tests {
+        rec := httptest.NewRecorder()
+        HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), tt.statusCode, "", nil, nil)
+
+        resp := decodeResponse(t, rec.Body)
+        if resp.Error == nil || resp.Error.Retryable != tt.want {
+            t.Errorf("Status %d: expected retryable=%v, got %+v", tt.statusCode, tt.want, resp.Error)
+        }
+    }
+
+    // Overrides can mark other statuses retryable for a single request.
+    override, _ := GetStatusConfig(http.StatusConflict)
+    override.Retryable = true
+    req := httptest.NewRequest(http.MethodPut, "/locks/1", nil)
+    req = req.WithContext(WithStatusOverrides(req.Context(), map[int]StatusConfig{http.StatusConflict: override}))
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, req, http.StatusConflict, "", nil, nil)
+    if resp := decodeResponse(t, rec.Body); resp.Error == nil || !resp.Error.Retryable {
+        t.Errorf("Expected overridden 409 to be retryable, got %+v", resp.Error)
+    }
+}
+
+func TestHTTPResponse_DeadlineLogAttrs(t *testing.T) {
+    var buf bytes.Buffer
+    withConfig(t, Config{Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+
+    ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+    defer cancel()
+    <-ctx.Done()
+
+    req := httptest.NewRequest(http.MethodGet, "/reports", nil).WithContext(ctx)
+    HTTPResponse(httptest.NewRecorder(), req, http.StatusGatewayTimeout, "", nil, nil)
+
+    var record map[string]interface{}
+    if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+        t.Fatalf("Failed to decode log record: %v", err)
+    }
+    if record["timed_out"] != true {
+        t.Errorf("Expected timed_out=true, got %v", record["timed_out"])
+    }
+    deadline, _ := ctx.Deadline()
+    if s, _ := record["deadline"].(string); s == "" {
+        t.Errorf("Expected a deadline attr, got %v", record["deadline"])
+    } else if got, err := time.Parse(time.RFC3339Nano, s); err != nil || !got.Equal(deadline) {
+        t.Errorf("Expected deadline %v, got %v", deadline, record["deadline"])
+    }
+    if ms, ok := record["deadline_remaining_ms"].(float64); !ok || ms > 0 {
+        t.Errorf("Expected non-positive deadline_remaining_ms, got %v", record["deadline_remaining_ms"])
+    }
+
+    // Requests without a deadline don't get the attrs.
+    buf.Reset()
+    HTTPResponse(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports", nil), http.StatusOK, "", nil, nil)
+    if strings.Contains(buf.String(), "deadline") {
+        t.Errorf("Expected no deadline attrs, got %q", buf.String())
+    }
+}
+
+func TestSetConfig_MaxUserAgentLen(t *testing.T) {
+    var buf bytes.Buffer
+    withConfig(t, Config{Logger: slog.New(slog.NewJSONHandler(&buf, nil)), MaxUserAgentLen: 10})
+
+    userAgent := "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36"
+    req := httptest.NewRequest(http.MethodGet, "/", nil)
+    req.Header.Set("User-Agent", userAgent)
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, req, http.StatusOK, "", map[string]string{"ua": req.UserAgent()}, nil)
+
+    var record map[string]interface{}
+    if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+        t.Fatalf("Failed to decode log record: %v", err)
+    }
+    if record["user_agent"] != "Mozilla/5." {
+        t.Errorf("Expected truncated user_agent in log, got %v", record["user_agent"])
+    }
+    if req.UserAgent() != userAgent {
+        t.Errorf("Expected request header untouched, got %q", req.UserAgent())
+    }
+    if !strings.Contains(rec.Body.String(), userAgent) {
+        t.Errorf("Expected response body untouched, got %s", rec.Body.String())
+    }
+}
+
+func TestSetConfig_ClassMessages(t *testing.T) {
+    classMessages := map[int]string{400: "Something was wrong with your request"}
+    withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), ClassMessages: classMessages})
+    classMessages[400] = "mutated after SetConfig"
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), 452, "", nil, nil)
+    if resp := decodeResponse(t, rec.Body); resp.Message != "Something was wrong with your request" {
+        t.Errorf("Expected custom class message for unmapped 452, got %q", resp.Message)
+    }
+
+    // Mapped codes and classes without a custom message are unaffected.
+    rec = httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusNotFound, "", nil, nil)
+    if resp := decodeResponse(t, rec.Body); resp.Message == "Something was wrong with your request" {
+        t.Error("Expected mapped 404 to keep its default message")
+    }
+    rec = httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), 599, "", nil, nil)
+    if resp := decodeResponse(t, rec.Body); resp.Message != "Server error occurred" {
+        t.Errorf("Expected built-in 5xx fallback, got %q", resp.Message)
+    }
+}
+
+func TestSetConfig_LogFullURI(t *testing.T) {
+    tests := []struct {
+        name       string
+        logFullURI bool
+        want       string
+    }{
+        {"path only", false, "/search"},
+        {"full URI", true, "/search?q=go&access_token=REDACTED&page=2&Password=REDACTED"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var buf bytes.Buffer
+            withConfig(t, Config{Logger: slog.New(slog.NewJSONHandler(&buf, nil)), LogFullURI: tt.logFullURI})
+
+            req := httptest.NewRequest(http.MethodGet, "/search?q=go&access_token=s3cr3t&page=2&Password=hunter2", nil)
+            HTTPResponse(httptest.NewRecorder(), req, http.StatusOK, "", nil, nil)
+
+            var record map[string]interface{}
+            if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+                t.Fatalf("Failed to decode log record: %v", err)
+            }
+            if record["path"] != tt.want {
+                t.Errorf("Expected path %q, got %v", tt.want, record["path"])
+            }
+            if strings.Contains(buf.String(), "s3cr3t") || strings.Contains(buf.String(), "hunter2") {
+                t.Errorf("Expected credentials to stay out of the log, got %q", buf.String())
+            }
+        })
+    }
+}
+
+func TestSetConfig_PostProcessPanicKeepsPointerFields(t *testing.T) {
+    withConfig(t, Config{
+        PostProcess: func(r *http.Request, resp *Response) {
+            resp.Error.Type = "hacked"
+            resp.Error.Details["field"] = "hacked"
+            panic("boom")
+        },
+    })
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodPost, "/", nil), http.StatusBadRequest, "", nil, map[string]string{"field": "email"})
+
+    resp := decodeResponse(t, rec.Body)
+    if resp.Error == nil || resp.Error.Type != "validation_error" {
+        t.Errorf("Expected error type validation_error after panic, got %+v", resp.Error)
+    }
+    if resp.Error != nil && resp.Error.Details["field"] != "email" {
+        t.Errorf("Expected details unchanged after panic, got %+v", resp.Error.Details)
+    }
+}
+
+func TestSetConfig_ClearsHooks(t *testing.T) {
+    withConfig(t, Config{
+        Resolver:    func(r *http.Request) *Config { return &Config{AllowEmptyMessage: true} },
+        PostProcess: func(r *http.Request, resp *Response) { resp.Message = "hooked" },
+        IDGenerator: func() string { return "fixed" },
+    })
+    SetConfig(Config{})
+
+    if defaultConfig.Resolver != nil || defaultConfig.PostProcess != nil || defaultConfig.IDGenerator != nil {
+        t.Fatal("Expected SetConfig without hooks to clear them")
+    }
+    if defaultConfig.Logger == nil {
+        t.Error("Expected a nil Logger to keep the current one")
+    }
+
+    rec := httptest.NewRecorder()
+    HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, "original", nil, nil)
+    if resp := decodeResponse(t, rec.Body); resp.Message != "original" {
+        t.Errorf("Expected cleared PostProcess not to run, got %q", resp.Message)
+    }
+}
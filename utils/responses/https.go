@@ -0,0 +1,46 @@
+package responses
+
+import (
+	"net/http"
+	"strings"
+)
+
+// isHTTPS reports whether r reached us over TLS, either directly or, behind a
+// TLS-terminating proxy, as reported by the first X-Forwarded-Proto value.
+// With Config.TrustedProxies set, X-Forwarded-Proto is only believed from a
+// trusted peer.
+func isHTTPS(cfg Config, r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if !forwardingTrusted(cfg, r) {
+		return false
+	}
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
+
+// RequireHTTPS only lets HTTPS requests through. Plain HTTP requests are
+// redirected to the same URL over HTTPS, 301 for GET and HEAD and 308 for
+// other methods so the method and body are kept. With
+// Config.RejectPlainHTTP set they get a 403 instead.
+func RequireHTTPS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := configFor(r)
+		if isHTTPS(cfg, r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if cfg.RejectPlainHTTP {
+			HTTPResponse(w, r, http.StatusForbidden, "HTTPS is required", nil, nil)
+			return
+		}
+
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), code)
+	})
+}
@@ -0,0 +1,123 @@
+package responses
+
+import (
+	"bytes"
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func TestRequireHTTPS_TLS(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://api.example.com/users", nil)
+	req.TLS = &tls.ConnectionState{}
+
+	rec := httptest.NewRecorder()
+	RequireHTTPS(okHandler()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected TLS request to pass, got %d", rec.Code)
+	}
+}
+
+func TestRequireHTTPS_ForwardedProto(t *testing.T) {
+	tests := []struct {
+		proto string
+		want  int
+	}{
+		{"https", http.StatusNoContent},
+		{"HTTPS", http.StatusNoContent},
+		{"https, http", http.StatusNoContent},
+		{"http", http.StatusMovedPermanently},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://api.example.com/users", nil)
+		req.Header.Set("X-Forwarded-Proto", tt.proto)
+
+		rec := httptest.NewRecorder()
+		RequireHTTPS(okHandler()).ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("X-Forwarded-Proto %q: expected %d, got %d", tt.proto, tt.want, rec.Code)
+		}
+	}
+}
+
+func TestRequireHTTPS_PlainHTTP(t *testing.T) {
+	tests := []struct {
+		method string
+		want   int
+	}{
+		{http.MethodGet, http.StatusMovedPermanently},
+		{http.MethodPost, http.StatusPermanentRedirect},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		RequireHTTPS(okHandler()).ServeHTTP(rec, httptest.NewRequest(tt.method, "http://api.example.com/users?page=2", nil))
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.method, tt.want, rec.Code)
+		}
+		if loc := rec.Header().Get("Location"); loc != "https://api.example.com/users?page=2" {
+			t.Errorf("%s: unexpected Location %q", tt.method, loc)
+		}
+	}
+}
+
+func TestRequireHTTPS_Reject(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), RejectPlainHTTP: true})
+
+	rec := httptest.NewRecorder()
+	RequireHTTPS(okHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://api.example.com/users", nil))
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected code %d, got %d", http.StatusForbidden, rec.Code)
+	}
+	if resp := decodeResponse(t, rec.Body); resp.Message != "HTTPS is required" {
+		t.Errorf("Unexpected message %q", resp.Message)
+	}
+	if rec.Header().Get("Location") != "" {
+		t.Error("Expected no redirect when rejecting")
+	}
+}
+
+func TestRequireHTTPS_TrustedProxies(t *testing.T) {
+	withConfig(t, Config{
+		Logger:         slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       int
+	}{
+		{"spoofed by a direct client", "203.0.113.9:4000", http.StatusMovedPermanently},
+		{"set by a trusted proxy", "10.0.0.1:4000", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://api.example.com/users", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-Proto", "https")
+
+			rec := httptest.NewRecorder()
+			RequireHTTPS(okHandler()).ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package responses
+
+import "strconv"
+
+// jsonAPIContentType is the media type required by the JSON:API spec.
+const jsonAPIContentType = "application/vnd.api+json"
+
+// jsonAPIDocument is the top-level JSON:API document. A document never holds
+// both data and errors, so success and error responses use different shapes.
+type jsonAPIDocument struct {
+	Data  interface{}       `json:"data"`
+	Meta  interface{}       `json:"meta,omitempty"`
+	Links map[string]string `json:"links,omitempty"`
+}
+
+type jsonAPIErrorDocument struct {
+	Errors []jsonAPIError    `json:"errors"`
+	Meta   interface{}       `json:"meta,omitempty"`
+	Links  map[string]string `json:"links,omitempty"`
+}
+
+// jsonAPIError is a JSON:API error object. Status is a string per the spec.
+type jsonAPIError struct {
+	Status string            `json:"status"`
+	Code   string            `json:"code,omitempty"`
+	Title  string            `json:"title,omitempty"`
+	Meta   map[string]string `json:"meta,omitempty"`
+}
+
+// newJSONAPIDocument maps the envelope onto JSON:API members: data, meta and
+// links carry over, and the error becomes a single error object whose code is the
+// error type, title is the message, and meta holds the details.
+func newJSONAPIDocument(resp Response) interface{} {
+	if resp.Error == nil {
+		return jsonAPIDocument{Data: resp.Data, Meta: resp.Meta, Links: resp.Links}
+	}
+	return jsonAPIErrorDocument{
+		Errors: []jsonAPIError{{
+			Status: strconv.Itoa(resp.StatusCode),
+			Code:   resp.Error.Type,
+			Title:  resp.Message,
+			Meta:   resp.Error.Details,
+		}},
+		Meta:  resp.Meta,
+		Links: resp.Links,
+	}
+}
@@ -0,0 +1,96 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJSONAPI_DataResponse(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), JSONAPI: true})
+
+	rec := httptest.NewRecorder()
+	send(rec, httptest.NewRequest(http.MethodGet, "/articles/1", nil), responseSpec{
+		statusCode: http.StatusOK,
+		data:       map[string]string{"type": "articles", "id": "1"},
+		meta:       map[string]int{"total": 1},
+	})
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/vnd.api+json" {
+		t.Errorf("Expected JSON:API content type, got %q", ct)
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if string(doc["data"]) != `{"id":"1","type":"articles"}` {
+		t.Errorf("Unexpected data member: %s", doc["data"])
+	}
+	if string(doc["meta"]) != `{"total":1}` {
+		t.Errorf("Unexpected meta member: %s", doc["meta"])
+	}
+	for _, member := range []string{"errors", "status", "statusCode", "message"} {
+		if _, ok := doc[member]; ok {
+			t.Errorf("Expected no %q member in a data document", member)
+		}
+	}
+}
+
+func TestJSONAPI_ErrorResponse(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), JSONAPI: true, ErrorsAsArray: true})
+
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, httptest.NewRequest(http.MethodPost, "/articles", nil), http.StatusBadRequest, "Title is required", nil, map[string]string{"field": "title"})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected code %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	if bytes.Contains(rec.Body.Bytes(), []byte(`"data"`)) {
+		t.Errorf("Expected no data member alongside errors, got %s", rec.Body.String())
+	}
+
+	var doc struct {
+		Errors []struct {
+			Status string            `json:"status"`
+			Code   string            `json:"code"`
+			Title  string            `json:"title"`
+			Meta   map[string]string `json:"meta"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(doc.Errors) != 1 {
+		t.Fatalf("Expected 1 error object, got %d", len(doc.Errors))
+	}
+	got := doc.Errors[0]
+	if got.Status != "400" || got.Code != "validation_error" || got.Title != "Title is required" || got.Meta["field"] != "title" {
+		t.Errorf("Unexpected error object: %+v", got)
+	}
+}
+
+func TestJSONAPI_LogsFormat(t *testing.T) {
+	tests := []struct {
+		jsonAPI bool
+		want    string
+	}{
+		{false, "format=json"},
+		{true, "format=jsonapi"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil)), JSONAPI: tt.jsonAPI})
+
+		HTTPResponse(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/articles", nil), http.StatusOK, "", nil, nil)
+
+		if !bytes.Contains(buf.Bytes(), []byte(tt.want)) {
+			t.Errorf("Expected %q in log, got %q", tt.want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package responses
+
+import (
+	"context"
+	"net/http"
+)
+
+// ItemResult is the outcome of a single item in a batch operation.
+type ItemResult struct {
+	ID         string      `json:"id,omitempty"`      // Caller-supplied item identifier, optional
+	Status     string      `json:"status"`            // "success" or "error", derived from StatusCode
+	StatusCode int         `json:"statusCode"`        // HTTP status code for this item
+	Message    string      `json:"message,omitempty"` // Human-readable message, optional
+	Data       interface{} `json:"data,omitempty"`    // Item payload, optional
+	Error      *ErrorInfo  `json:"error,omitempty"`   // Item error details, optional
+}
+
+// MultiStatus responds 207 with per-item results under data. Each item's
+// status label and error type are filled in from its status code. The
+// top-level status is "success" when every item succeeded, "error" when every
+// item failed, and "partial" otherwise.
+func MultiStatus(w http.ResponseWriter, r *http.Request, results []ItemResult) {
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+
+	cfg := configFor(r)
+
+	items := make([]ItemResult, len(results))
+	failed := 0
+	for i, item := range results {
+		items[i] = normalizeItemResult(ctx, cfg, item)
+		if items[i].Status == "error" {
+			failed++
+		}
+	}
+
+	status := "success"
+	switch {
+	case failed == 0:
+	case failed == len(items):
+		status = "error"
+	default:
+		status = "partial"
+	}
+
+	send(w, r, responseSpec{
+		statusCode: http.StatusMultiStatus,
+		data:       items,
+		status:     status,
+	})
+}
+
+func normalizeItemResult(ctx context.Context, cfg Config, item ItemResult) ItemResult {
+	item.StatusCode = validateStatusCode(item.StatusCode)
+	summary := buildResponse(ctx, cfg, item.StatusCode, "", nil, nil)
+
+	item.Status = summary.Status
+	item.Message = sanitizeMessage(item.Message, cfg.MaxMessageLen)
+	if summary.Error != nil {
+		if item.Message == "" {
+			item.Message = summary.Message
+		}
+		errorInfo := *summary.Error
+		if item.Error != nil {
+			// Copy so the caller's ErrorInfo is left untouched.
+			errorInfo = *item.Error
+			if errorInfo.Type == "" {
+				errorInfo.Type = summary.Error.Type
+			}
+			errorInfo.Retryable = errorInfo.Retryable || summary.Error.Retryable
+		}
+		errorInfo.detailsKey = cfg.ErrorDetailsKey
+		item.Error = &errorInfo
+	}
+	return item
+}
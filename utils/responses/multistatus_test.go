@@ -0,0 +1,102 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMultiStatus_Mixed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/batch", nil)
+
+	MultiStatus(rec, req, []ItemResult{
+		{ID: "1", StatusCode: http.StatusCreated, Data: map[string]string{"name": "ada"}},
+		{ID: "2", StatusCode: http.StatusConflict, Message: "Email already registered"},
+		{ID: "3", StatusCode: http.StatusBadRequest, Error: &ErrorInfo{Details: map[string]string{"field": "email"}}},
+	})
+
+	if rec.Code != http.StatusMultiStatus {
+		t.Errorf("Expected code %d, got %d", http.StatusMultiStatus, rec.Code)
+	}
+
+	var body struct {
+		Status     string       `json:"status"`
+		StatusCode int          `json:"statusCode"`
+		Message    string       `json:"message"`
+		Data       []ItemResult `json:"data"`
+		Error      *ErrorInfo   `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if body.Status != "partial" {
+		t.Errorf("Expected aggregate status 'partial', got %q", body.Status)
+	}
+	if body.Message == "" {
+		t.Error("Expected default message for 207")
+	}
+	if len(body.Data) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(body.Data))
+	}
+	if body.Data[0].Status != "success" || body.Data[0].Error != nil {
+		t.Errorf("Expected item 1 to succeed, got %+v", body.Data[0])
+	}
+	if body.Data[1].Status != "error" || body.Data[1].Error == nil || body.Data[1].Error.Type != "conflict" {
+		t.Errorf("Expected item 2 to be a conflict, got %+v", body.Data[1])
+	}
+	if body.Data[2].Error == nil || body.Data[2].Error.Type != "validation_error" || body.Data[2].Error.Details["field"] != "email" {
+		t.Errorf("Expected item 3 validation_error with details, got %+v", body.Data[2].Error)
+	}
+}
+
+func TestMultiStatus_Aggregate(t *testing.T) {
+	tests := []struct {
+		name  string
+		codes []int
+		want  string
+	}{
+		{"all succeeded", []int{http.StatusOK, http.StatusCreated}, "success"},
+		{"all failed", []int{http.StatusBadRequest, http.StatusNotFound}, "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make([]ItemResult, len(tt.codes))
+			for i, code := range tt.codes {
+				results[i] = ItemResult{StatusCode: code}
+			}
+
+			rec := httptest.NewRecorder()
+			MultiStatus(rec, httptest.NewRequest(http.MethodPost, "/batch", nil), results)
+
+			resp := decodeResponse(t, rec.Body)
+			if resp.Status != tt.want {
+				t.Errorf("Expected status %q, got %q", tt.want, resp.Status)
+			}
+		})
+	}
+}
+
+func TestMultiStatus_SanitizesItemMessages(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))})
+
+	rec := httptest.NewRecorder()
+	MultiStatus(rec, httptest.NewRequest(http.MethodPost, "/bulk", nil), []ItemResult{
+		{ID: "1", StatusCode: http.StatusConflict, Message: "Email\r\nX-Injected: yes"},
+	})
+
+	var body struct {
+		Data []ItemResult `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if got := body.Data[0].Message; got != "EmailX-Injected: yes" {
+		t.Errorf("Expected control characters stripped, got %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// OmitEmpty converts data into a generic JSON value with zero-valued object
+// fields removed, regardless of struct tags. null, false, 0, "", and empty
+// objects or arrays count as zero. Array elements are pruned recursively but
+// never removed, so indexes are preserved.
+//
+// This is lossy: the result is made of maps, slices and json.Number, so type
+// information and field order are gone, and legitimately meaningful zero
+// values (a count of 0, an explicit false) disappear too.
+func OmitEmpty(data interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return pruneZero(generic), nil
+}
+
+func pruneZero(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			field = pruneZero(field)
+			if isZeroJSON(field) {
+				delete(v, key)
+				continue
+			}
+			v[key] = field
+		}
+		return v
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = pruneZero(elem)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isZeroJSON(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case json.Number:
+		f, err := v.Float64()
+		return err == nil && f == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
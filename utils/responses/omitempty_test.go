@@ -0,0 +1,51 @@
+package responses
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOmitEmpty(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+		Zip  string `json:"zip"`
+	}
+	type user struct {
+		Name     string   `json:"name"`
+		Age      int      `json:"age"`
+		Active   bool     `json:"active"`
+		Nickname *string  `json:"nickname"`
+		Tags     []string `json:"tags"`
+		Address  address  `json:"address"`
+		Previous address  `json:"previous"`
+	}
+
+	pruned, err := OmitEmpty(user{Name: "Ada", Address: address{City: "London"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	encoded, _ := json.Marshal(pruned)
+	want := `{"address":{"city":"London"},"name":"Ada"}`
+	if string(encoded) != want {
+		t.Errorf("Expected %s, got %s", want, encoded)
+	}
+}
+
+func TestOmitEmpty_KeepsArrayElements(t *testing.T) {
+	pruned, err := OmitEmpty([]map[string]int{{"a": 0}, {"a": 1}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	encoded, _ := json.Marshal(pruned)
+	if string(encoded) != `[{},{"a":1}]` {
+		t.Errorf("Expected array indexes preserved, got %s", encoded)
+	}
+}
+
+func TestOmitEmpty_Unmarshalable(t *testing.T) {
+	if _, err := OmitEmpty(make(chan int)); err == nil {
+		t.Error("Expected error for unmarshalable data")
+	}
+}
@@ -0,0 +1,39 @@
+package responses
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// Pagination is the meta member sent by Paginated.
+type Pagination struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"perPage"`
+	TotalItems int `json:"totalItems"`
+	TotalPages int `json:"totalPages"`
+}
+
+// Paginated responds 200 with one page of a list as data and the pagination
+// under meta. TotalPages is computed from TotalItems and PerPage; any value
+// the caller set is ignored. A PerPage of zero or less is a handler bug and
+// is logged and answered with a 500.
+func Paginated(w http.ResponseWriter, r *http.Request, data interface{}, page Pagination) {
+	if page.PerPage <= 0 {
+		cfg := configFor(r)
+		ctx, reqInfo := requestScope(cfg, r)
+		cfg.errorLogger().LogAttrs(ctx, slog.LevelError, "Invalid pagination",
+			slog.Int("per_page", page.PerPage),
+			slog.String("path", reqInfo.Path),
+			slog.String("request_id", reqInfo.RequestID),
+		)
+		HTTPResponse(w, withRequestID(r, reqInfo.RequestID), http.StatusInternalServerError, "", nil, nil)
+		return
+	}
+
+	page.TotalPages = (page.TotalItems + page.PerPage - 1) / page.PerPage
+	Write(w, r, HTTPResponseOpts{
+		StatusCode: http.StatusOK,
+		Data:       data,
+		Meta:       page,
+	})
+}
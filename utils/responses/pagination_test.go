@@ -0,0 +1,78 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPaginated(t *testing.T) {
+	tests := []struct {
+		name       string
+		totalItems int
+		perPage    int
+		wantPages  int
+	}{
+		{"partial last page", 45, 20, 3},
+		{"exact pages", 40, 20, 2},
+		{"empty list", 0, 20, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Paginated(rec, httptest.NewRequest(http.MethodGet, "/users?page=2", nil), []string{"ada", "grace"}, Pagination{
+				Page:       2,
+				PerPage:    tt.perPage,
+				TotalItems: tt.totalItems,
+				TotalPages: 99, // ignored
+			})
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("Expected code %d, got %d", http.StatusOK, rec.Code)
+			}
+			var body struct {
+				Data []string   `json:"data"`
+				Meta Pagination `json:"meta"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			want := Pagination{Page: 2, PerPage: tt.perPage, TotalItems: tt.totalItems, TotalPages: tt.wantPages}
+			if body.Meta != want {
+				t.Errorf("Expected meta %+v, got %+v", want, body.Meta)
+			}
+			if len(body.Data) != 2 {
+				t.Errorf("Expected data to be passed through, got %v", body.Data)
+			}
+		})
+	}
+}
+
+func TestPaginated_InvalidPerPage(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+
+	rec := httptest.NewRecorder()
+	Paginated(rec, httptest.NewRequest(http.MethodGet, "/users", nil), nil, Pagination{Page: 1, PerPage: 0})
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected code %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if !strings.Contains(buf.String(), "Invalid pagination") {
+		t.Errorf("Expected invalid pagination to be logged, got %q", buf.String())
+	}
+}
+
+func TestHTTPResponse_NoMetaWithoutPagination(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/users/7", nil), http.StatusOK, "", map[string]string{"id": "7"}, nil)
+
+	if strings.Contains(rec.Body.String(), `"meta"`) {
+		t.Errorf("Expected no meta member, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,35 @@
+package responses
+
+import "net/http"
+
+// problemContentType is the media type for RFC 7807 problem details.
+const problemContentType = "application/problem+json"
+
+// problemDetails is an RFC 7807 problem object. Details is an extension
+// member carrying the error details.
+type problemDetails struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Details  map[string]string `json:"details,omitempty"`
+}
+
+// newProblemDetails maps an error envelope onto a problem object: the error
+// type, prefixed by Config.ProblemTypeBaseURI, becomes type and the message
+// becomes detail. title is the standard status text.
+func newProblemDetails(cfg Config, resp Response, instance string) problemDetails {
+	title := http.StatusText(resp.StatusCode)
+	if title == "" {
+		title = resp.Message
+	}
+	return problemDetails{
+		Type:     cfg.ProblemTypeBaseURI + resp.Error.Type,
+		Title:    title,
+		Status:   resp.StatusCode,
+		Detail:   resp.Message,
+		Instance: instance,
+		Details:  resp.Error.Details,
+	}
+}
@@ -0,0 +1,69 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProblemDetails_ErrorResponse(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{
+		Logger:             slog.New(slog.NewTextHandler(&buf, nil)),
+		ProblemDetails:     true,
+		ProblemTypeBaseURI: "https://api.example.com/problems/",
+	})
+
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, httptest.NewRequest(http.MethodPost, "/users/42/email", nil), http.StatusConflict, "Email already registered", nil, map[string]string{"field": "email"})
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected code %d, got %d", http.StatusConflict, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Expected problem+json content type, got %q", ct)
+	}
+
+	var problem map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	want := map[string]interface{}{
+		"type":     "https://api.example.com/problems/conflict",
+		"title":    "Conflict",
+		"status":   float64(http.StatusConflict),
+		"detail":   "Email already registered",
+		"instance": "/users/42/email",
+	}
+	for key, value := range want {
+		if problem[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, problem[key])
+		}
+	}
+	if details, ok := problem["details"].(map[string]interface{}); !ok || details["field"] != "email" {
+		t.Errorf("Expected details extension, got %v", problem["details"])
+	}
+	if _, ok := problem["error"]; ok {
+		t.Error("Expected no envelope error member")
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("format=problem")) {
+		t.Errorf("Expected format=problem in log, got %q", buf.String())
+	}
+}
+
+func TestProblemDetails_SuccessUnchanged(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), ProblemDetails: true})
+
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil), http.StatusOK, "", map[string]string{"id": "42"}, nil)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected application/json for success, got %q", ct)
+	}
+	if resp := decodeResponse(t, rec.Body); resp.Status != "success" || resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected standard envelope, got %+v", resp)
+	}
+}
@@ -0,0 +1,69 @@
+package responses
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// WriteRawJSON sends payload as-is, for bodies that are already serialized
+// such as cached or proxied JSON. It sets the same headers as HTTPResponse and
+// logs the response, but does not wrap payload in the envelope. Payloads are
+// trusted to be valid JSON unless Config.ValidateRawJSON is set, in which case
+// invalid ones are logged and replaced with a 500 envelope.
+func WriteRawJSON(w http.ResponseWriter, r *http.Request, statusCode int, payload []byte) {
+	statusCode = validateStatusCode(statusCode)
+	cfg := configFor(r)
+
+	if w == nil {
+		warnNilWriter(cfg, r, "JSON response called with nil ResponseWriter", statusCode)
+		return
+	}
+
+	ctx, reqInfo := requestScope(cfg, r)
+	logAttrs := []slog.Attr{
+		slog.Int("statusCode", statusCode),
+		slog.String("method", reqInfo.Method),
+		slog.String("path", reqInfo.Path),
+		slog.String("route", reqInfo.Route),
+		slog.String("user_agent", reqInfo.UserAgent),
+		slog.String("remote_ip", reqInfo.RemoteIP),
+		slog.String("request_id", reqInfo.RequestID),
+		slog.Int("response_bytes", len(payload)),
+	}
+
+	if cfg.ValidateRawJSON && !json.Valid(payload) {
+		cfg.errorLogger().LogAttrs(ctx, slog.LevelError, "Invalid raw JSON response", logAttrs...)
+		HTTPResponse(w, withRequestID(r, reqInfo.RequestID), http.StatusInternalServerError, "", nil, nil)
+		return
+	}
+
+	setResponseHeaders(w)
+	setRequestIDHeader(cfg, w, reqInfo)
+	hasBody := bodyAllowedForStatus(statusCode)
+	if hasBody {
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		if len(cfg.SigningKey) > 0 {
+			w.Header().Set("X-Signature", signBody(cfg.SigningKey, payload))
+		}
+	}
+
+	w.WriteHeader(statusCode)
+
+	if hasBody && !isHeadRequest(r) {
+		if _, err := w.Write(payload); err != nil {
+			logAttrs = append(logAttrs, slog.Any("write_error", err))
+			cfg.errorLogger().LogAttrs(ctx, slog.LevelError, "Failed to write JSON response", logAttrs...)
+			return
+		}
+	}
+
+	if cfg.FlushAfterWrite {
+		_ = http.NewResponseController(w).Flush()
+	}
+
+	if !loggingDisabled(ctx) {
+		cfg.Logger.LogAttrs(ctx, cfg.responseLogLevel(ctx, statusCode), responseLogMessage(statusCode), logAttrs...)
+	}
+}
@@ -0,0 +1,54 @@
+package responses
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteRawJSON_WritesBytesUnchanged(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+
+	payload := []byte(`{"id": 7,   "name":"ada"}` + "\n")
+	rec := httptest.NewRecorder()
+	WriteRawJSON(rec, httptest.NewRequest(http.MethodGet, "/users/7", nil), http.StatusOK, payload)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), payload) {
+		t.Errorf("Expected body %q, got %q", payload, rec.Body.Bytes())
+	}
+	if rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", rec.Header().Get("Content-Type"))
+	}
+	if rec.Header().Get("Content-Length") != strconv.Itoa(len(payload)) {
+		t.Errorf("Expected Content-Length %d, got %q", len(payload), rec.Header().Get("Content-Length"))
+	}
+	if !strings.Contains(buf.String(), "statusCode=200") || !strings.Contains(buf.String(), "path=/users/7") {
+		t.Errorf("Expected response to be logged, got %q", buf.String())
+	}
+}
+
+func TestWriteRawJSON_Validate(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil)), ValidateRawJSON: true})
+
+	rec := httptest.NewRecorder()
+	WriteRawJSON(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, []byte(`{"id":`))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected code %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if resp := decodeResponse(t, rec.Body); resp.Error == nil || resp.Error.Type != "internal_server_error" {
+		t.Errorf("Expected 500 envelope, got %+v", resp)
+	}
+	if !strings.Contains(buf.String(), "Invalid raw JSON response") {
+		t.Errorf("Expected invalid payload to be logged, got %q", buf.String())
+	}
+}
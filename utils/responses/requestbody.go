@@ -0,0 +1,108 @@
+package responses
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxCapturedBodyBytes bounds how much of a request body is kept for logging.
+const maxCapturedBodyBytes = 2048
+
+// capturedBody is the leading part of a request body kept for error logs.
+type capturedBody struct {
+	snippet   []byte
+	truncated bool
+}
+
+// redactedJSONField matches a credential-like JSON member, from redactedQueryParams,
+// up to the end of its value. A string value cut off by truncation still matches.
+var redactedJSONField = func() *regexp.Regexp {
+	names := make([]string, 0, len(redactedQueryParams))
+	for name := range redactedQueryParams {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	slices.Sort(names)
+	return regexp.MustCompile(`(?i)("(?:` + strings.Join(names, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+}()
+
+// redactBody makes a body snippet safe to log: credential-like JSON members
+// and form fields become "REDACTED", control characters are stripped, and
+// binary data is replaced by a placeholder.
+func redactBody(snippet []byte, contentType string, truncated bool) []byte {
+	if truncated {
+		// Drop a multi-byte character split by the size limit.
+		for i := 0; i < utf8.UTFMax-1 && len(snippet) > 0 && !utf8.Valid(snippet); i++ {
+			snippet = snippet[:len(snippet)-1]
+		}
+	}
+	if !utf8.Valid(snippet) || bytes.IndexByte(snippet, 0) >= 0 {
+		return []byte(fmt.Sprintf("[%d bytes of binary data]", len(snippet)))
+	}
+
+	text := string(snippet)
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" {
+		text = redactQuery(text)
+	} else {
+		text = redactedJSONField.ReplaceAllString(text, `$1"REDACTED"`)
+	}
+	return []byte(sanitizeMessage(text, 0))
+}
+
+// readCloser pairs a reader with the Close of the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// CaptureRequestBody keeps the first few KB of each request body so that, with
+// Config.LogErrorRequestBody enabled, error responses can log what the client
+// sent. Credential-like JSON and form fields are redacted in the kept copy;
+// handlers still read the complete, unmodified body.
+func CaptureRequestBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Read one byte past the limit to know whether the snippet is complete.
+		prefix, err := io.ReadAll(io.LimitReader(r.Body, maxCapturedBodyBytes+1))
+		truncated := len(prefix) > maxCapturedBodyBytes
+		snippet := prefix
+		if truncated {
+			snippet = prefix[:maxCapturedBodyBytes]
+		}
+		captured := &capturedBody{
+			snippet:   redactBody(snippet, r.Header.Get("Content-Type"), truncated),
+			truncated: truncated,
+		}
+
+		var rest io.Reader = r.Body
+		if err != nil {
+			// Let the handler observe the same read error.
+			rest = &errReader{err: err}
+		}
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(prefix), rest), Closer: r.Body}
+
+		ctx := context.WithValue(r.Context(), requestBodyKey, captured)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type errReader struct{ err error }
+
+func (e *errReader) Read([]byte) (int, error) { return 0, e.err }
+
+// requestBodySnippet returns the body captured by CaptureRequestBody, if any.
+func requestBodySnippet(ctx context.Context) (*capturedBody, bool) {
+	captured, ok := ctx.Value(requestBodyKey).(*capturedBody)
+	return captured, ok
+}
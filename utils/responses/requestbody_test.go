@@ -0,0 +1,112 @@
+package responses
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCaptureRequestBody_LogsOnErrorOnly(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil)), LogErrorRequestBody: true})
+
+	handler := CaptureRequestBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"email":"not-an-email"}` {
+			t.Errorf("Expected handler to read the full body, got %q", body)
+		}
+		status := http.StatusOK
+		if r.URL.Path == "/invalid" {
+			status = http.StatusBadRequest
+		}
+		HTTPResponse(w, r, status, "", nil, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/invalid", strings.NewReader(`{"email":"not-an-email"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !strings.Contains(buf.String(), `request_body="{\"email\":\"not-an-email\"}"`) {
+		t.Errorf("Expected body snippet for 400, got %q", buf.String())
+	}
+
+	buf.Reset()
+	req = httptest.NewRequest(http.MethodPost, "/valid", strings.NewReader(`{"email":"not-an-email"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if strings.Contains(buf.String(), "request_body") {
+		t.Errorf("Expected no body snippet for 200, got %q", buf.String())
+	}
+}
+
+func TestCaptureRequestBody_Truncates(t *testing.T) {
+	large := strings.Repeat("a", maxCapturedBodyBytes+100)
+
+	var got []byte
+	var captured *capturedBody
+	handler := CaptureRequestBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured, _ = requestBodySnippet(r.Context())
+		got, _ = io.ReadAll(r.Body)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(large)))
+
+	if string(got) != large {
+		t.Errorf("Expected handler to read all %d bytes, got %d", len(large), len(got))
+	}
+	if captured == nil || len(captured.snippet) != maxCapturedBodyBytes || !captured.truncated {
+		t.Errorf("Expected truncated snippet of %d bytes, got %+v", maxCapturedBodyBytes, captured)
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		truncated   bool
+		want        string
+	}{
+		{"json password", `{"email":"a@b.c","password":"hunter2"}`, "application/json", false, `{"email":"a@b.c","password":"REDACTED"}`},
+		{"json case and spacing", `{"Password" : "hun\"ter2", "pin": 1}`, "application/json", false, `{"Password" : "REDACTED", "pin": 1}`},
+		{"json non-string value", `{"token": 12345, "a": 1}`, "application/json", false, `{"token": "REDACTED", "a": 1}`},
+		{"json value cut off", `{"user":"x","password":"hunt`, "application/json", true, `{"user":"x","password":"REDACTED"`},
+		{"form", "user=ada&password=hunter2&remember=1", "application/x-www-form-urlencoded; charset=utf-8", false, "user=ada&password=REDACTED&remember=1"},
+		{"control characters", "{\"note\":\"a\nb\x1b[31m\"}", "application/json", false, `{"note":"ab[31m"}`},
+		{"binary", "\x89PNG\x00\x01\x02", "image/png", false, "[7 bytes of binary data]"},
+		{"rune split by truncation", "{\"name\":\"caf\xc3", "application/json", true, `{"name":"caf`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(redactBody([]byte(tt.body), tt.contentType, tt.truncated)); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCaptureRequestBody_RedactsPassword(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil)), LogErrorRequestBody: true})
+
+	body := `{"email":"ada@example.com","password":"hunter2"}`
+	handler := CaptureRequestBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ := io.ReadAll(r.Body)
+		if string(got) != body {
+			t.Errorf("Expected handler to read the unredacted body, got %q", got)
+		}
+		HTTPResponse(w, r, http.StatusUnauthorized, "", nil, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("Expected the password to stay out of the log, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), `\"password\":\"REDACTED\"`) {
+		t.Errorf("Expected password to be logged as REDACTED, got %q", buf.String())
+	}
+}
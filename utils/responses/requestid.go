@@ -0,0 +1,89 @@
+package responses
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// defaultRequestIDHeader carries the request ID in both directions unless
+// Config.RequestIDHeader names another header.
+const defaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds incoming request IDs so clients can't bloat logs.
+const maxRequestIDLen = 128
+
+// newRequestID returns 128 random bits, hex-encoded.
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII without spaces, so
+// an incoming ID can't forge log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// AssignRequestID settles the request ID once, before the handler runs: the
+// client's ID when valid, otherwise a generated one. The ID is echoed in the
+// response header right away and stored on the request context, where
+// RequestIDFromContext finds it for handler logs and every response the
+// package sends reuses it.
+func AssignRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := configFor(r)
+		id := requestIDFor(cfg, r)
+		w.Header().Set(cfg.requestIDHeader(), id)
+		next.ServeHTTP(w, withRequestID(r, id))
+	})
+}
+
+// RequestIDFromContext returns the ID stored by AssignRequestID, or "" when
+// the middleware didn't run.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// withRequestID returns r carrying id in its context, so responses sent for r
+// later, such as a fallback 500, reuse the ID already logged.
+func withRequestID(r *http.Request, id string) *http.Request {
+	if r == nil || id == "" {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), requestIDKey, id))
+}
+
+// requestIDFor returns the ID stored on the request context, the client's
+// request ID when it is valid, or a new ID from Config.IDGenerator (128 random
+// bits by default).
+func requestIDFor(cfg Config, r *http.Request) string {
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		return id
+	}
+	if id := r.Header.Get(cfg.requestIDHeader()); validRequestID(id) {
+		return id
+	}
+	if cfg.IDGenerator != nil {
+		return cfg.IDGenerator()
+	}
+	return newRequestID()
+}
+
+// setRequestIDHeader echoes the request ID back to the client.
+func setRequestIDHeader(cfg Config, w http.ResponseWriter, reqInfo RequestInfo) {
+	if reqInfo.RequestID != "" {
+		w.Header().Set(cfg.requestIDHeader(), reqInfo.RequestID)
+	}
+}
@@ -0,0 +1,209 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRequestID_Generated(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{
+		Logger:      slog.New(slog.NewJSONHandler(&buf, nil)),
+		IDGenerator: func() string { return "generated-1" },
+	})
+
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, httptest.NewRequest(http.MethodGet, "/users", nil), http.StatusOK, "", nil, nil)
+
+	if got := rec.Header().Get("X-Request-ID"); got != "generated-1" {
+		t.Errorf("Expected generated X-Request-ID, got %q", got)
+	}
+	if resp := decodeResponse(t, rec.Body); resp.RequestID != "generated-1" {
+		t.Errorf("Expected requestId in body, got %q", resp.RequestID)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Failed to decode log record: %v", err)
+	}
+	if record["request_id"] != "generated-1" {
+		t.Errorf("Expected request_id in log, got %v", record["request_id"])
+	}
+}
+
+func TestRequestID_Incoming(t *testing.T) {
+	withConfig(t, Config{
+		Logger:      slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+		IDGenerator: func() string { return "generated-1" },
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		want     string
+	}{
+		{"valid", "abc-123", "abc-123"},
+		{"contains spaces", "abc 123", "generated-1"},
+		{"contains newline", "abc\nlevel=ERROR", "generated-1"},
+		{"too long", strings.Repeat("a", 129), "generated-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			req.Header.Set("X-Request-ID", tt.incoming)
+
+			rec := httptest.NewRecorder()
+			HTTPResponse(rec, req, http.StatusOK, "", nil, nil)
+
+			if got := rec.Header().Get("X-Request-ID"); got != tt.want {
+				t.Errorf("Expected X-Request-ID %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	hex128 := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := newRequestID()
+		if !hex128.MatchString(id) {
+			t.Fatalf("Expected 32 hex characters, got %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("Duplicate request ID %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestRequestID_CustomHeader(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{
+		Logger:          slog.New(slog.NewJSONHandler(&buf, nil)),
+		RequestIDHeader: "X-Correlation-ID",
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("X-Correlation-ID", "corr-42")
+	req.Header.Set("X-Request-ID", "ignored")
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, req, http.StatusOK, "", nil, nil)
+
+	if got := rec.Header().Get("X-Correlation-ID"); got != "corr-42" {
+		t.Errorf("Expected X-Correlation-ID to be echoed, got %q", got)
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "" {
+		t.Errorf("Expected no X-Request-ID header, got %q", got)
+	}
+	if resp := decodeResponse(t, rec.Body); resp.RequestID != "corr-42" {
+		t.Errorf("Expected requestId corr-42 in body, got %q", resp.RequestID)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Failed to decode log record: %v", err)
+	}
+	if record["request_id"] != "corr-42" {
+		t.Errorf("Expected request_id corr-42 in log, got %v", record["request_id"])
+	}
+}
+
+func TestAssignRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	calls := 0
+	withConfig(t, Config{
+		Logger:      slog.New(slog.NewJSONHandler(&buf, nil)),
+		IDGenerator: func() string { calls++; return "generated-1" },
+	})
+
+	var fromContext string
+	handler := AssignRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromContext = RequestIDFromContext(r.Context())
+		Paginated(w, r, nil, Pagination{Page: 1})
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if fromContext != "generated-1" {
+		t.Errorf("Expected the handler to see the ID, got %q", fromContext)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the ID to be generated once, got %d calls", calls)
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "generated-1" {
+		t.Errorf("Expected X-Request-ID generated-1, got %q", got)
+	}
+	if resp := decodeResponse(t, rec.Body); resp.RequestID != "generated-1" {
+		t.Errorf("Expected requestId generated-1, got %q", resp.RequestID)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected the pagination error and the response to be logged, got %q", buf.String())
+	}
+	for _, line := range lines {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log record: %v", err)
+		}
+		if record["request_id"] != "generated-1" {
+			t.Errorf("Expected request_id on %q, got %v", record["msg"], record["request_id"])
+		}
+	}
+}
+
+func TestRequestIDFromContext_Empty(t *testing.T) {
+	if got := RequestIDFromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()); got != "" {
+		t.Errorf("Expected no ID without the middleware, got %q", got)
+	}
+}
+
+func TestRequestID_SameIDOnFallback(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+
+	rec := httptest.NewRecorder()
+	Paginated(rec, httptest.NewRequest(http.MethodGet, "/users", nil), nil, Pagination{Page: 1})
+
+	id := rec.Header().Get("X-Request-ID")
+	if id == "" || strings.Count(buf.String(), `"request_id":"`+id+`"`) != 2 {
+		t.Errorf("Expected both log lines to carry the response's ID %q, got %q", id, buf.String())
+	}
+}
+
+func TestRequestID_NilWriterAndEncodeLogs(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("X-Request-ID", "incoming-1")
+	HTTPResponse(nil, req, http.StatusOK, "", nil, nil)
+	WriteRawJSON(nil, req, http.StatusOK, []byte(`{}`))
+	StreamList(nil, req, http.StatusOK, nil, nil)
+
+	req = withRequestID(httptest.NewRequest(http.MethodGet, "/users", nil), "assigned-1")
+	HTTPResponse(nil, req, http.StatusOK, "", nil, nil)
+	Encode(req.Context(), &bytes.Buffer{}, http.StatusOK, "", nil, nil)
+
+	want := []string{"incoming-1", "incoming-1", "incoming-1", "assigned-1", "assigned-1"}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d log lines, got %q", len(want), buf.String())
+	}
+	for i, line := range lines {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log record: %v", err)
+		}
+		if record["request_id"] != want[i] {
+			t.Errorf("Expected request_id %q on %q, got %v", want[i], record["msg"], record["request_id"])
+		}
+	}
+}
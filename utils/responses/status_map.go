@@ -1,17 +1,23 @@
 package responses
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 )
 
-// StatusConfig defines log level, default message, and error type for an HTTP status code.
+// StatusConfig defines log level, default message, error type, and retryability for an HTTP status code.
 type StatusConfig struct {
 	LogLevel       slog.Level
 	DefaultMessage string
 	ErrorType      string
+	Retryable      bool // Whether clients may retry the same request later
 }
 
+// StatusClientClosedRequest is the non-standard status, popularized by nginx,
+// for a request the client abandoned before the response was ready.
+const StatusClientClosedRequest = 499
+
 // statusConfigMap maps HTTP status codes to their respective configuration.
 var statusConfigMap = map[int]StatusConfig{
 	// Success responses
@@ -31,6 +37,16 @@ var statusConfigMap = map[int]StatusConfig{
 		DefaultMessage: "Request completed successfully",
 		LogLevel:       slog.LevelInfo,
 	},
+	http.StatusMultiStatus: {
+		DefaultMessage: "The request was processed with individual results per item",
+		LogLevel:       slog.LevelInfo,
+	},
+
+	// Redirection responses
+	http.StatusNotModified: {
+		DefaultMessage: "The resource has not been modified",
+		LogLevel:       slog.LevelInfo,
+	},
 
 	// Client error responses
 	http.StatusBadRequest: {
@@ -58,11 +74,21 @@ var statusConfigMap = map[int]StatusConfig{
 		LogLevel:       slog.LevelWarn,
 		ErrorType:      "method_not_allowed",
 	},
+	http.StatusNotAcceptable: {
+		DefaultMessage: "The requested representation is not available",
+		LogLevel:       slog.LevelWarn,
+		ErrorType:      "not_acceptable",
+	},
 	http.StatusConflict: {
 		DefaultMessage: "The request could not be completed due to a conflict with the current state of the resource",
 		LogLevel:       slog.LevelWarn,
 		ErrorType:      "conflict",
 	},
+	http.StatusRequestEntityTooLarge: {
+		DefaultMessage: "The request body is too large",
+		LogLevel:       slog.LevelWarn,
+		ErrorType:      "payload_too_large",
+	},
 	http.StatusUnprocessableEntity: {
 		DefaultMessage: "The request was well-formed but could not be processed due to semantic errors",
 		LogLevel:       slog.LevelWarn,
@@ -72,6 +98,12 @@ var statusConfigMap = map[int]StatusConfig{
 		DefaultMessage: "Too many requests have been made in a given amount of time",
 		LogLevel:       slog.LevelWarn,
 		ErrorType:      "rate_limit_exceeded",
+		Retryable:      true,
+	},
+	StatusClientClosedRequest: {
+		DefaultMessage: "The client closed the request before the server could respond",
+		LogLevel:       slog.LevelWarn,
+		ErrorType:      "client_closed_request",
 	},
 
 	// Server error responses
@@ -94,11 +126,13 @@ var statusConfigMap = map[int]StatusConfig{
 		DefaultMessage: "The server is currently unable to handle the request due to temporary overload or maintenance",
 		LogLevel:       slog.LevelError,
 		ErrorType:      "service_unavailable",
+		Retryable:      true,
 	},
 	http.StatusGatewayTimeout: {
 		DefaultMessage: "The server did not receive a timely response from an upstream server",
 		LogLevel:       slog.LevelError,
 		ErrorType:      "gateway_timeout",
+		Retryable:      true,
 	},
 	http.StatusHTTPVersionNotSupported: {
 		DefaultMessage: "The server does not support the HTTP protocol version used in the request",
@@ -112,15 +146,19 @@ var statusConfigMap = map[int]StatusConfig{
 	},
 }
 
-func getMessageForStatus(statusCode int, providedMessage string) string {
+func getMessageForStatus(ctx context.Context, statusCode int, providedMessage string, classMessages map[int]string) string {
 	if providedMessage != "" {
 		return providedMessage
 	}
 
-	if config, exists := statusConfigMap[statusCode]; exists {
+	if config, exists := lookupStatusConfig(ctx, statusCode); exists {
 		return config.DefaultMessage
 	}
 
+	if message := classMessages[statusCode/100*100]; message != "" {
+		return message
+	}
+
 	switch {
 	case statusCode >= 200 && statusCode < 300:
 		return "Request completed successfully"
@@ -140,3 +178,13 @@ func GetStatusConfig(statusCode int) (StatusConfig, bool) {
 	cfg, exists := statusConfigMap[statusCode]
 	return cfg, exists
 }
+
+// DefaultStatusConfigs returns a copy of the built-in status configuration.
+// Changes to the returned map do not affect the package.
+func DefaultStatusConfigs() map[int]StatusConfig {
+	configs := make(map[int]StatusConfig, len(statusConfigMap))
+	for code, cfg := range statusConfigMap {
+		configs[code] = cfg
+	}
+	return configs
+}
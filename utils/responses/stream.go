@@ -0,0 +1,165 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// streamFlushInterval is the number of items written between flushes.
+const streamFlushInterval = 100
+
+// streamErrorTrailer is the HTTP trailer that reports the first failure of a
+// stream whose status was already sent.
+const streamErrorTrailer = "X-Stream-Error"
+
+// StreamList writes a list envelope whose data array is streamed from items
+// instead of being built in memory. Items are encoded as they arrive and the
+// response is flushed every streamFlushInterval items. meta, when non-nil, is
+// written after the data array.
+//
+// Headers are sent before the first item, so failures mid-stream can't change
+// the status. They are logged, and the first one is reported in the
+// X-Stream-Error trailer so clients can detect a partial list. The stream
+// stops early if the request context is cancelled; producers should watch
+// r.Context() so they don't block on an abandoned channel.
+func StreamList(w http.ResponseWriter, r *http.Request, statusCode int, items <-chan interface{}, meta interface{}) {
+	statusCode = validateStatusCode(statusCode)
+	cfg := configFor(r)
+
+	if w == nil {
+		warnNilWriter(cfg, r, "JSON stream called with nil ResponseWriter", statusCode)
+		return
+	}
+
+	ctx, reqInfo := requestScope(cfg, r)
+	resp := buildResponse(ctx, cfg, statusCode, "", nil, nil)
+	logAttrs := responseLogAttrs(resp, reqInfo)
+
+	suffix, err := streamSuffix(cfg, resp, meta)
+	if err != nil {
+		logAttrs = append(logAttrs, slog.Any("marshal_error", err))
+		cfg.errorLogger().LogAttrs(ctx, slog.LevelError, "Failed to marshal stream metadata", logAttrs...)
+		HTTPResponse(w, withRequestID(r, reqInfo.RequestID), http.StatusInternalServerError, "", nil, nil)
+		return
+	}
+
+	setResponseHeaders(w)
+	setRequestIDHeader(cfg, w, reqInfo)
+	w.Header().Set("Trailer", streamErrorTrailer)
+	w.WriteHeader(resp.StatusCode)
+
+	rc := http.NewResponseController(w)
+	count := 0
+
+	fail := func(msg, errKey string, err error) {
+		attrs := append(logAttrs, slog.Int("items", count), slog.Any(errKey, err))
+		cfg.errorLogger().LogAttrs(ctx, slog.LevelError, msg, attrs...)
+		if w.Header().Get(streamErrorTrailer) == "" {
+			w.Header().Set(streamErrorTrailer, msg+": "+err.Error())
+		}
+	}
+
+	if _, err := w.Write(streamPrefix(resp)); err != nil {
+		fail("Failed to write stream", "write_error", err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			fail("Stream aborted", "write_error", ctx.Err())
+			return
+
+		case item, ok := <-items:
+			if !ok {
+				if _, err := w.Write(suffix); err != nil {
+					fail("Failed to write stream", "write_error", err)
+					return
+				}
+				flushStream(rc)
+				logAttrs = append(logAttrs, slog.Int("items", count))
+				if !loggingDisabled(ctx) {
+					cfg.Logger.LogAttrs(ctx, cfg.responseLogLevel(ctx, resp.StatusCode), responseLogMessage(resp.StatusCode), logAttrs...)
+				}
+				return
+			}
+
+			encoded, err := json.Marshal(item)
+			if err != nil {
+				// Skip the item so the array stays valid JSON.
+				fail("Failed to marshal stream item", "marshal_error", err)
+				continue
+			}
+
+			chunk := encoded
+			if count > 0 {
+				chunk = append([]byte{','}, encoded...)
+			}
+			if _, err := w.Write(chunk); err != nil {
+				fail("Failed to write stream", "write_error", err)
+				return
+			}
+
+			count++
+			if count%streamFlushInterval == 0 {
+				flushStream(rc)
+			}
+		}
+	}
+}
+
+// streamPrefix renders the envelope up to the opening bracket of the data array.
+func streamPrefix(resp Response) []byte {
+	var buf bytes.Buffer
+	status, _ := json.Marshal(resp.Status)
+	message, _ := json.Marshal(resp.Message)
+
+	buf.WriteString(`{"status":`)
+	buf.Write(status)
+	buf.WriteString(`,"statusCode":`)
+	buf.WriteString(strconv.Itoa(resp.StatusCode))
+	buf.WriteString(`,"message":`)
+	buf.Write(message)
+	buf.WriteString(`,"data":[`)
+	return buf.Bytes()
+}
+
+// streamSuffix renders the envelope after the data array.
+func streamSuffix(cfg Config, resp Response, meta interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(']')
+
+	if meta != nil {
+		encoded, err := json.Marshal(meta)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`,"meta":`)
+		buf.Write(encoded)
+	}
+
+	if resp.Error != nil {
+		key, value := `,"error":`, interface{}(resp.Error)
+		if cfg.ErrorsAsArray {
+			key, value = `,"errors":`, []*ErrorInfo{resp.Error}
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(key)
+		buf.Write(encoded)
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// flushStream pushes buffered bytes to the client. Writers that can't flush
+// return http.ErrNotSupported and simply keep buffering.
+func flushStream(rc *http.ResponseController) {
+	_ = rc.Flush()
+}
@@ -0,0 +1,127 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamList(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+
+	items := make(chan interface{})
+	go func() {
+		defer close(items)
+		for i := 0; i < 1000; i++ {
+			items <- map[string]int{"id": i}
+		}
+	}()
+
+	StreamList(rec, req, http.StatusOK, items, map[string]int{"total": 1000})
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if !rec.Flushed {
+		t.Error("Expected stream to be flushed")
+	}
+
+	var body struct {
+		Status     string           `json:"status"`
+		StatusCode int              `json:"statusCode"`
+		Message    string           `json:"message"`
+		Data       []map[string]int `json:"data"`
+		Meta       map[string]int   `json:"meta"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode streamed body: %v", err)
+	}
+	if body.Status != "success" || body.StatusCode != http.StatusOK || body.Message == "" {
+		t.Errorf("Unexpected envelope: %+v", body)
+	}
+	if len(body.Data) != 1000 {
+		t.Fatalf("Expected 1000 items, got %d", len(body.Data))
+	}
+	if body.Data[999]["id"] != 999 {
+		t.Errorf("Expected last item id 999, got %v", body.Data[999])
+	}
+	if body.Meta["total"] != 1000 {
+		t.Errorf("Expected meta total 1000, got %v", body.Meta)
+	}
+}
+
+func TestStreamList_SkipsUnmarshalableItems(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+
+	items := make(chan interface{}, 3)
+	items <- 1
+	items <- make(chan int)
+	items <- 2
+	close(items)
+
+	StreamList(rec, req, http.StatusOK, items, nil)
+
+	resp := decodeResponse(t, rec.Body)
+	list, ok := resp.Data.([]interface{})
+	if !ok || len(list) != 2 {
+		t.Errorf("Expected 2 items after skipping the bad one, got %+v", resp.Data)
+	}
+}
+
+// flakyWriter fails every body write after the first okWrites.
+type flakyWriter struct {
+	*httptest.ResponseRecorder
+	okWrites int
+}
+
+func (f *flakyWriter) Write(p []byte) (int, error) {
+	if f.okWrites == 0 {
+		return 0, errors.New("connection reset by peer")
+	}
+	f.okWrites--
+	return f.ResponseRecorder.Write(p)
+}
+
+func TestStreamList_ErrorTrailer(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))})
+
+	items := make(chan interface{}, 3)
+	items <- 1
+	items <- 2
+	items <- 3
+	close(items)
+
+	w := &flakyWriter{ResponseRecorder: httptest.NewRecorder(), okWrites: 2}
+	StreamList(w, httptest.NewRequest(http.MethodGet, "/items", nil), http.StatusOK, items, nil)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status to stay %d, got %d", http.StatusOK, w.Code)
+	}
+	trailer := w.Result().Trailer.Get("X-Stream-Error")
+	if !strings.Contains(trailer, "Failed to write stream") || !strings.Contains(trailer, "connection reset by peer") {
+		t.Errorf("Expected X-Stream-Error trailer, got %q", trailer)
+	}
+}
+
+func TestStreamList_NoErrorTrailerOnSuccess(t *testing.T) {
+	items := make(chan interface{}, 1)
+	items <- 1
+	close(items)
+
+	rec := httptest.NewRecorder()
+	StreamList(rec, httptest.NewRequest(http.MethodGet, "/items", nil), http.StatusOK, items, nil)
+
+	if rec.Header().Get("Trailer") != "X-Stream-Error" {
+		t.Errorf("Expected trailer to be declared, got %q", rec.Header().Get("Trailer"))
+	}
+	if got := rec.Result().Trailer.Get("X-Stream-Error"); got != "" {
+		t.Errorf("Expected empty trailer on success, got %q", got)
+	}
+}
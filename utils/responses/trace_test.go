@@ -0,0 +1,51 @@
+package responses
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type spanKey struct{}
+
+type fakeSpan struct {
+	traceID, spanID string
+}
+
+// recordingTraceContext stands in for an OpenTelemetry lookup such as
+// trace.SpanContextFromContext.
+func recordingTraceContext(ctx context.Context) (string, string, bool) {
+	span, ok := ctx.Value(spanKey{}).(fakeSpan)
+	if !ok {
+		return "", "", false
+	}
+	return span.traceID, span.spanID, true
+}
+
+func TestTraceContext_LogAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil)), TraceContext: recordingTraceContext})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	ctx := context.WithValue(req.Context(), spanKey{}, fakeSpan{
+		traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		spanID:  "00f067aa0ba902b7",
+	})
+	HTTPResponse(httptest.NewRecorder(), req.WithContext(ctx), http.StatusOK, "", nil, nil)
+
+	logged := buf.String()
+	if !strings.Contains(logged, "trace_id=4bf92f3577b34da6a3ce929d0e0e4736") || !strings.Contains(logged, "span_id=00f067aa0ba902b7") {
+		t.Errorf("Expected trace and span IDs in log, got %q", logged)
+	}
+
+	// No active span: no attributes.
+	buf.Reset()
+	HTTPResponse(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil), http.StatusOK, "", nil, nil)
+	if strings.Contains(buf.String(), "trace_id") {
+		t.Errorf("Expected no trace attrs without a span, got %q", buf.String())
+	}
+}
@@ -1,24 +1,106 @@
 package responses
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 // Response represents a standard HTTP JSON response structure.
 type Response struct {
-	Status     string      `json:"status"`               // "success" or "error"
-	StatusCode int         `json:"statusCode"`           // HTTP status code
-	Message    string      `json:"message"`              // Human-readable message
-	Data       interface{} `json:"data,omitempty"`       // Payload data, optional
-	Error      *ErrorInfo  `json:"error,omitempty"`      // Error details, optional
+	Status     string            `json:"status"`               // "success" or "error" ("partial" for mixed batches)
+	StatusCode int               `json:"statusCode"`           // HTTP status code
+	Message    string            `json:"message"`              // Human-readable message
+	Data       interface{}       `json:"data,omitempty"`       // Payload data, optional
+	Meta       interface{}       `json:"meta,omitempty"`       // Response metadata such as counts, optional
+	Links      map[string]string `json:"links,omitempty"`      // Related URLs keyed by relation, optional
+	Error      *ErrorInfo        `json:"error,omitempty"`      // Error details, optional
+	RequestID  string            `json:"requestId,omitempty"`  // Correlation ID, also sent in the request ID header
+}
+
+// errorListResponse is the wire form of Response under Config.ErrorsAsArray,
+// with the error sent as a one-element errors array.
+type errorListResponse struct {
+	Status     string            `json:"status"`
+	StatusCode int               `json:"statusCode"`
+	Message    string            `json:"message"`
+	Data       interface{}       `json:"data,omitempty"`
+	Meta       interface{}       `json:"meta,omitempty"`
+	Links      map[string]string `json:"links,omitempty"`
+	Errors     []ErrorInfo       `json:"errors,omitempty"`
+	RequestID  string            `json:"requestId,omitempty"`
+}
+
+func newErrorListResponse(resp Response) errorListResponse {
+	list := errorListResponse{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Message:    resp.Message,
+		Data:       resp.Data,
+		Meta:       resp.Meta,
+		Links:      resp.Links,
+		RequestID:  resp.RequestID,
+	}
+	if resp.Error != nil {
+		list.Errors = []ErrorInfo{*resp.Error}
+	}
+	return list
 }
 
 // ErrorInfo provides structured details about an error.
 type ErrorInfo struct {
-	Type    string            `json:"type"`               // Error type identifier (e.g., "validation_error")
-	Details map[string]string `json:"details,omitempty"`  // Additional error details, optional
+	Type      string            `json:"type"`               // Error type identifier (e.g., "validation_error")
+	Details   map[string]string `json:"details,omitempty"`  // Additional error details, optional
+	Retryable bool              `json:"retryable"`          // Whether the same request may succeed if retried later
+
+	detailsKey string // JSON key for Details when renamed via Config.ErrorDetailsKey
+}
+
+// MarshalJSON writes Details under the configured key, "details" by default.
+// Keys already used by the error object fall back to "details" so they can't
+// shadow its other members.
+func (e ErrorInfo) MarshalJSON() ([]byte, error) {
+	type plain ErrorInfo
+	switch e.detailsKey {
+	case "", "details", "type", "retryable":
+		return json.Marshal(plain(e))
+	}
+	if len(e.Details) == 0 {
+		return json.Marshal(plain(e))
+	}
+
+	details, err := json.Marshal(e.Details)
+	if err != nil {
+		return nil, err
+	}
+	key, err := json.Marshal(e.detailsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := plain(e)
+	rest.Details = nil
+	encoded, err := json.Marshal(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Splice the renamed member in before the closing brace.
+	var buf bytes.Buffer
+	buf.Write(encoded[:len(encoded)-1])
+	buf.WriteByte(',')
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.Write(details)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
 }
 
 // RequestInfo holds extracted info from the HTTP request for logging or tracing.
 type RequestInfo struct {
 	Method    string // HTTP method (GET, POST, etc.)
-	Path      string // Request path (URL.Path)
+	Path      string // Request path (URL.Path), plus the redacted query with Config.LogFullURI
+	Route     string // Matched route pattern (e.g. "GET /users/{id}"), or Path if none
 	UserAgent string // User-Agent header string
 	RemoteIP  string // Client IP address
+	RequestID string // Incoming request ID header, or a generated ID
 }
@@ -0,0 +1,179 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite_Options(t *testing.T) {
+	var buf bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+
+	rec := httptest.NewRecorder()
+	Write(rec, httptest.NewRequest(http.MethodGet, "/users?page=2", nil), HTTPResponseOpts{
+		StatusCode: http.StatusOK,
+		Message:    "Users listed",
+		Data:       []string{"ada", "grace"},
+		Meta:       map[string]int{"page": 2},
+		Links:      map[string]string{"next": "/users?page=3"},
+		Headers:    http.Header{"X-Total-Count": {"42"}, "Content-Type": {"text/plain"}},
+		LogAttrs:   []slog.Attr{slog.String("tenant", "acme")},
+	})
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if string(body["message"]) != `"Users listed"` || string(body["data"]) != `["ada","grace"]` {
+		t.Errorf("Unexpected message or data: %s %s", body["message"], body["data"])
+	}
+	if string(body["meta"]) != `{"page":2}` {
+		t.Errorf("Unexpected meta: %s", body["meta"])
+	}
+	if string(body["links"]) != `{"next":"/users?page=3"}` {
+		t.Errorf("Unexpected links: %s", body["links"])
+	}
+	if rec.Header().Get("X-Total-Count") != "42" {
+		t.Errorf("Expected custom header, got %v", rec.Header())
+	}
+	if rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected managed Content-Type to win, got %q", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(buf.String(), "tenant=acme") {
+		t.Errorf("Expected custom log attr, got %q", buf.String())
+	}
+}
+
+func TestWrite_ErrorDefaults(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Write(rec, httptest.NewRequest(http.MethodPost, "/users", nil), HTTPResponseOpts{
+		StatusCode: http.StatusBadRequest,
+		Details:    map[string]string{"field": "email"},
+	})
+
+	resp := decodeResponse(t, rec.Body)
+	config, _ := GetStatusConfig(http.StatusBadRequest)
+	if resp.Message != config.DefaultMessage {
+		t.Errorf("Expected default message, got %q", resp.Message)
+	}
+	if resp.Error == nil || resp.Error.Details["field"] != "email" {
+		t.Errorf("Expected details, got %+v", resp.Error)
+	}
+	if resp.Meta != nil || resp.Links != nil {
+		t.Errorf("Expected no meta or links, got %+v", resp)
+	}
+}
+
+func TestWrite_ReturnsEffectiveStatus(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), MaxResponseBytes: 512})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	tests := []struct {
+		name string
+		opts HTTPResponseOpts
+		want int
+	}{
+		{"valid", HTTPResponseOpts{StatusCode: http.StatusCreated}, http.StatusCreated},
+		{"clamped invalid code", HTTPResponseOpts{StatusCode: 42}, http.StatusInternalServerError},
+		{"unmarshalable data", HTTPResponseOpts{StatusCode: http.StatusOK, Data: make(chan int)}, http.StatusInternalServerError},
+		{"oversized body", HTTPResponseOpts{StatusCode: http.StatusOK, Data: strings.Repeat("x", 1024)}, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			got := Write(rec, req, tt.opts)
+			if got != tt.want || rec.Code != tt.want {
+				t.Errorf("Expected status %d, returned %d and wrote %d", tt.want, got, rec.Code)
+			}
+		})
+	}
+
+	if got := Write(nil, req, HTTPResponseOpts{StatusCode: http.StatusOK}); got != 0 {
+		t.Errorf("Expected 0 for nil writer, got %d", got)
+	}
+}
+
+func TestWrite_MatchesHTTPResponse(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+
+	viaWrite := httptest.NewRecorder()
+	Write(viaWrite, req, HTTPResponseOpts{StatusCode: http.StatusCreated, Data: "ok"})
+
+	viaHTTPResponse := httptest.NewRecorder()
+	HTTPResponse(viaHTTPResponse, req, http.StatusCreated, "", "ok", nil)
+
+	if viaWrite.Code != viaHTTPResponse.Code || viaWrite.Body.String() != viaHTTPResponse.Body.String() {
+		t.Errorf("Expected identical responses, got %d %q and %d %q",
+			viaWrite.Code, viaWrite.Body.String(), viaHTTPResponse.Code, viaHTTPResponse.Body.String())
+	}
+}
+
+func TestWrite_RetryAfter(t *testing.T) {
+	at := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name       string
+		opts       HTTPResponseOpts
+		wantHeader string
+	}{
+		{"seconds on 429", HTTPResponseOpts{StatusCode: http.StatusTooManyRequests, RetryAfter: 10 * time.Second}, "10"},
+		{"HTTP-date on 503", HTTPResponseOpts{StatusCode: http.StatusServiceUnavailable, RetryAt: at}, "Sat, 01 Mar 2025 11:00:00 GMT"},
+		{"duration wins over date", HTTPResponseOpts{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Second, RetryAt: at}, "1"},
+		{"allowed on redirects", HTTPResponseOpts{StatusCode: http.StatusFound, RetryAfter: 5 * time.Second}, "5"},
+		{"ignored on 200", HTTPResponseOpts{StatusCode: http.StatusOK, RetryAfter: 5 * time.Second}, ""},
+		{"ignored on 500", HTTPResponseOpts{StatusCode: http.StatusInternalServerError, RetryAfter: 5 * time.Second}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			withConfig(t, Config{Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+
+			rec := httptest.NewRecorder()
+			Write(rec, httptest.NewRequest(http.MethodGet, "/", nil), tt.opts)
+
+			if got := rec.Header().Get("Retry-After"); got != tt.wantHeader {
+				t.Errorf("Expected Retry-After %q, got %q", tt.wantHeader, got)
+			}
+
+			var record map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("Failed to decode log record: %v", err)
+			}
+			got, logged := record["retry_after"]
+			if tt.wantHeader == "" {
+				if logged {
+					t.Errorf("Expected no retry_after attribute, got %v", got)
+				}
+			} else if got != tt.wantHeader {
+				t.Errorf("Expected retry_after %q, got %v", tt.wantHeader, got)
+			}
+		})
+	}
+}
+
+func TestWrite_HeadersNotAliased(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), NegotiateXML: true})
+
+	vary := make([]string, 1, 4)
+	vary[0] = "Origin"
+	headers := http.Header{"Vary": vary}
+
+	rec := httptest.NewRecorder()
+	Write(rec, httptest.NewRequest(http.MethodGet, "/", nil), HTTPResponseOpts{StatusCode: http.StatusOK, Headers: headers})
+
+	if got := rec.Header().Values("Vary"); len(got) < 2 || got[0] != "Origin" || got[1] != "Accept" {
+		t.Errorf("Expected Vary Origin and Accept on the response, got %v", got)
+	}
+	if spare := vary[:2][1]; spare != "" {
+		t.Errorf("Expected the caller's header slice to be left alone, found %q appended", spare)
+	}
+}
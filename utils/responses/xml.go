@@ -0,0 +1,193 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const xmlContentType = "application/xml; charset=utf-8"
+
+// acceptRange is one media range of an Accept header with its q value.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept splits an Accept header into lowercased media ranges.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		rng, params, _ := strings.Cut(part, ";")
+		rng = strings.ToLower(strings.TrimSpace(rng))
+		if rng == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(name, "q") {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: rng, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the q value the Accept header gives mediaType, using
+// the most specific matching range: type/subtype, then type/*, then */*.
+// An empty header accepts everything.
+func acceptQuality(header, mediaType string) float64 {
+	if strings.TrimSpace(header) == "" {
+		return 1
+	}
+	typ, _, _ := strings.Cut(mediaType, "/")
+
+	best, specificity := 0.0, -1
+	for _, rng := range parseAccept(header) {
+		s := -1
+		switch rng.mediaType {
+		case mediaType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		}
+		if s > specificity {
+			best, specificity = rng.q, s
+		}
+	}
+	return best
+}
+
+// jsonQuality returns the best q the Accept header gives a JSON type:
+// application/json or any +json type, which covers the JSON:API and problem
+// details media types.
+func jsonQuality(header string) float64 {
+	q := acceptQuality(header, "application/json")
+	for _, rng := range parseAccept(header) {
+		if strings.HasSuffix(rng.mediaType, "+json") {
+			q = max(q, rng.q)
+		}
+	}
+	return q
+}
+
+// negotiateFormat picks between JSON and XML for the Accept header. JSON wins
+// ties. ok is false when the client accepts neither.
+func negotiateFormat(accept string) (xmlPreferred, ok bool) {
+	jsonQ := jsonQuality(accept)
+	xmlQ := max(acceptQuality(accept, "application/xml"), acceptQuality(accept, "text/xml"))
+	if jsonQ <= 0 && xmlQ <= 0 {
+		return false, false
+	}
+	return xmlQ > jsonQ, true
+}
+
+// xmlName turns a JSON key into a valid XML element name, replacing invalid
+// characters with underscores.
+func xmlName(key string) string {
+	var b strings.Builder
+	for i, c := range key {
+		valid := c == '_' || unicode.IsLetter(c) || (i > 0 && (c == '-' || c == '.' || unicode.IsDigit(c)))
+		if i == 0 && unicode.IsDigit(c) {
+			// Names can't start with a digit; keep it behind an underscore.
+			b.WriteByte('_')
+			valid = true
+		}
+		if !valid {
+			c = '_'
+		}
+		b.WriteRune(c)
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// encodeXML writes v as XML under a <response> root. v is encoded as JSON
+// first and converted token by token, so the XML carries the same fields, in
+// the same order, as the JSON envelope. Arrays become repeated <item>
+// elements and null becomes an empty element.
+func encodeXML(v interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	if err := writeXMLValue(dec, enc, "response"); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// writeXMLValue reads the next JSON value from dec and writes it as an
+// element called name.
+func writeXMLValue(dec *json.Decoder, enc *xml.Encoder, name string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		for dec.More() {
+			child := "item"
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = xmlName(key.(string))
+			}
+			if err := writeXMLValue(dec, enc, child); err != nil {
+				return err
+			}
+		}
+		// Consume the closing delimiter.
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case string:
+		err = enc.EncodeToken(xml.CharData(t))
+	case json.Number:
+		err = enc.EncodeToken(xml.CharData(t.String()))
+	case bool:
+		err = enc.EncodeToken(xml.CharData(strconv.FormatBool(t)))
+	case nil:
+	default:
+		err = errors.New("responses: unexpected JSON token")
+	}
+	if err != nil {
+		return err
+	}
+
+	return enc.EncodeToken(start.End())
+}
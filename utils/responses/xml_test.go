@@ -0,0 +1,207 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		accept  string
+		wantXML bool
+		wantOK  bool
+	}{
+		{"", false, true},
+		{"*/*", false, true},
+		{"application/json", false, true},
+		{"application/xml", true, true},
+		{"text/xml", true, true},
+		{"application/xml, application/json", false, true},
+		{"application/json;q=0.5, application/xml", true, true},
+		{"application/*;q=0.2, application/xml", true, true},
+		{"text/html, */*;q=0.8", false, true},
+		{"application/xml, */*;q=0", true, true},
+		{"text/csv", false, false},
+		{"application/json;q=0", false, false},
+		{"application/vnd.api+json", false, true},
+		{"application/problem+json", false, true},
+		{"application/problem+json;q=0.5, application/xml", true, true},
+	}
+
+	for _, tt := range tests {
+		gotXML, gotOK := negotiateFormat(tt.accept)
+		if gotXML != tt.wantXML || gotOK != tt.wantOK {
+			t.Errorf("negotiateFormat(%q) = (%v, %v), want (%v, %v)", tt.accept, gotXML, gotOK, tt.wantXML, tt.wantOK)
+		}
+	}
+}
+
+func TestXMLName(t *testing.T) {
+	tests := map[string]string{
+		"email":      "email",
+		"statusCode": "statusCode",
+		"user-id":    "user-id",
+		"2fa":        "_2fa",
+		"first name": "first_name",
+		"":           "_",
+	}
+	for key, want := range tests {
+		if got := xmlName(key); got != want {
+			t.Errorf("xmlName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestHTTPResponse_NegotiateXML(t *testing.T) {
+	var logs bytes.Buffer
+	withConfig(t, Config{Logger: slog.New(slog.NewJSONHandler(&logs, nil)), NegotiateXML: true})
+
+	type xmlResponse struct {
+		XMLName    xml.Name `xml:"response"`
+		Status     string   `xml:"status"`
+		StatusCode int      `xml:"statusCode"`
+		Message    string   `xml:"message"`
+		Error      struct {
+			Type    string `xml:"type"`
+			Details struct {
+				Field string `xml:"field"`
+			} `xml:"details"`
+		} `xml:"error"`
+	}
+
+	t.Run("application/json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		HTTPResponse(rec, req, http.StatusOK, "", map[string]string{"id": "1"}, nil)
+
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("Expected JSON content type, got %q", got)
+		}
+		if got := rec.Header().Get("Vary"); !strings.Contains(got, "Accept") {
+			t.Errorf("Expected Vary to include Accept, got %q", got)
+		}
+		decodeResponse(t, rec.Body)
+	})
+
+	t.Run("application/xml", func(t *testing.T) {
+		logs.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/users", nil)
+		req.Header.Set("Accept", "application/xml")
+		rec := httptest.NewRecorder()
+		HTTPResponse(rec, req, http.StatusBadRequest, "Invalid <user>", nil, map[string]string{"field": "email"})
+
+		if got := rec.Header().Get("Content-Type"); got != xmlContentType {
+			t.Errorf("Expected XML content type, got %q", got)
+		}
+		var resp xmlResponse
+		if err := xml.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode XML response: %v\n%s", err, rec.Body.String())
+		}
+		if resp.Status != "error" || resp.StatusCode != http.StatusBadRequest || resp.Message != "Invalid <user>" {
+			t.Errorf("Unexpected envelope %+v", resp)
+		}
+		if resp.Error.Type != "validation_error" || resp.Error.Details.Field != "email" {
+			t.Errorf("Unexpected error %+v", resp.Error)
+		}
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+			t.Fatalf("Failed to decode log record: %v", err)
+		}
+		if record["format"] != "xml" {
+			t.Errorf("Expected format=xml in log, got %v", record["format"])
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Accept", "text/csv")
+		rec := httptest.NewRecorder()
+		HTTPResponse(rec, req, http.StatusOK, "", map[string]string{"id": "1"}, nil)
+
+		if rec.Code != http.StatusNotAcceptable {
+			t.Fatalf("Expected 406, got %d", rec.Code)
+		}
+		resp := decodeResponse(t, rec.Body)
+		if resp.Error == nil || resp.Error.Type != "not_acceptable" || resp.Error.Details["accept"] != "text/csv" {
+			t.Errorf("Expected not_acceptable error with the Accept header, got %+v", resp.Error)
+		}
+		if resp.Data != nil {
+			t.Errorf("Expected no data on 406, got %v", resp.Data)
+		}
+	})
+}
+
+func TestHTTPResponse_XMLDataOrderAndArrays(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), NegotiateXML: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Accept", "application/xml")
+	req.Header.Set("X-Request-ID", "req-1")
+	rec := httptest.NewRecorder()
+	data := []struct {
+		Name  string  `json:"name"`
+		Email *string `json:"email"`
+	}{{Name: "Ada"}, {Name: "Grace"}}
+	HTTPResponse(rec, req, http.StatusOK, "OK", data, nil)
+
+	want := xml.Header + `<response><status>success</status><statusCode>200</statusCode><message>OK</message>` +
+		`<data><item><name>Ada</name><email></email></item><item><name>Grace</name><email></email></item></data>` +
+		`<requestId>req-1</requestId></response>` + "\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("Unexpected XML body\nwant: %s\ngot:  %s", want, got)
+	}
+}
+
+func TestHTTPResponse_NegotiateXMLDisabled(t *testing.T) {
+	withConfig(t, Config{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Accept", "text/csv")
+	rec := httptest.NewRecorder()
+	HTTPResponse(rec, req, http.StatusOK, "", nil, nil)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected Accept to be ignored, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestHTTPResponse_NegotiateXMLWithJSONMediaTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		accept string
+		code   int
+		want   string
+	}{
+		{"JSON:API", Config{JSONAPI: true}, jsonAPIContentType, http.StatusOK, jsonAPIContentType},
+		{"problem details", Config{ProblemDetails: true}, problemContentType, http.StatusNotFound, problemContentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Logger = slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+			tt.cfg.NegotiateXML = true
+			withConfig(t, tt.cfg)
+
+			req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			HTTPResponse(rec, req, tt.code, "", nil, nil)
+
+			if rec.Code != tt.code {
+				t.Errorf("Expected %d, got %d", tt.code, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.want {
+				t.Errorf("Expected Content-Type %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Package testutil holds helpers for testing code that uses the responses package.
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sync"
+)
+
+// Record is one decoded log record, keyed by attribute name. Numbers decode
+// as float64.
+type Record map[string]interface{}
+
+// Logs collects the records written by the logger returned from CaptureLogs.
+type Logs struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write implements io.Writer for the JSON handler.
+func (l *Logs) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+// Records decodes everything logged so far, oldest first.
+func (l *Logs) Records() ([]Record, error) {
+	l.mu.Lock()
+	data := bytes.Clone(l.buf.Bytes())
+	l.mu.Unlock()
+
+	var records []Record
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// CaptureLogs returns a logger that records everything at Debug and above in
+// memory, and the Logs to read the records back from.
+func CaptureLogs() (*slog.Logger, *Logs) {
+	logs := &Logs{}
+	handler := slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(handler), logs
+}
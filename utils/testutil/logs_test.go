@@ -0,0 +1,45 @@
+package testutil
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/utils/responses"
+)
+
+func TestCaptureLogs_HTTPResponse(t *testing.T) {
+	logger, logs := CaptureLogs()
+	responses.SetConfig(responses.Config{Logger: logger})
+	t.Cleanup(func() { responses.SetConfig(responses.Config{Logger: slog.Default()}) })
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	req.RemoteAddr = "203.0.113.9:51234"
+	responses.HTTPResponse(httptest.NewRecorder(), req, http.StatusNotFound, "", nil, nil)
+
+	records, err := logs.Records()
+	if err != nil {
+		t.Fatalf("Failed to read records: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+
+	record := records[0]
+	if record["remote_ip"] != "203.0.113.9" {
+		t.Errorf("Expected remote_ip 203.0.113.9, got %v", record["remote_ip"])
+	}
+	if record["statusCode"] != float64(http.StatusNotFound) {
+		t.Errorf("Expected statusCode 404, got %v", record["statusCode"])
+	}
+}
+
+func TestCaptureLogs_Empty(t *testing.T) {
+	_, logs := CaptureLogs()
+
+	records, err := logs.Records()
+	if err != nil || len(records) != 0 {
+		t.Errorf("Expected no records, got %v (%v)", records, err)
+	}
+}